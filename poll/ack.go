@@ -0,0 +1,71 @@
+package poll
+
+import "fmt"
+
+// ackError is an ErrAckRequired for a vote held until the voter acknowledges
+// the terms of the poll.
+type ackError struct {
+	terms  string
+	option string
+}
+
+func (e ackError) Error() string {
+	return fmt.Sprintf("%v: %s", ErrAckRequired, e.terms)
+}
+
+func (e ackError) Is(target error) bool {
+	return target == ErrAckRequired
+}
+
+// acked reports whether the user acknowledged the terms of the poll, or the
+// poll has none.
+func (p pollEntry) acked(userId string) bool {
+	if p.Ack == "" {
+		return true
+	}
+	for _, uId := range p.Acked {
+		if uId == userId {
+			return true
+		}
+	}
+	return false
+}
+
+// hold keeps the vote of a user yet to acknowledge the terms of the poll,
+// to be cast by !poll ack. It must be called with the mutex held.
+func (p *pollEntry) hold(userId string, index int) error {
+	if p.held == nil {
+		p.held = make(map[string]int)
+	}
+	p.held[userId] = index
+	return ackError{terms: p.Ack, option: p.Options[index-1].Text}
+}
+
+// pollAck records that the user acknowledges the terms of the poll, and
+// casts the vote held until then, if any.
+func (pl *Poller) pollAck(roomId, userId, userName string) string {
+	pl.mutex.Lock()
+	defer pl.unlock()
+
+	poll, ok := pl.polls[roomId]
+	if !ok {
+		return "There is no poll."
+	}
+	if poll.Ack == "" {
+		return "The poll has no terms to acknowledge."
+	}
+	if !poll.acked(userId) {
+		poll.Acked = append(poll.Acked, userId)
+		poll.audit(userId, "acknowledged the terms of the poll")
+	}
+
+	index, ok := poll.held[userId]
+	if !ok {
+		return "Acknowledged, your votes will count."
+	}
+	delete(poll.held, userId)
+	if _, err := pl.castVote(roomId, userId, userName, index); err != nil {
+		return errorMessage(err)
+	}
+	return fmt.Sprintf("Acknowledged, vote recorded for %s", poll.Options[index-1].Text)
+}
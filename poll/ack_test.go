@@ -0,0 +1,47 @@
+package poll
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestAck(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.start("room", `-ack="I understand this is binding"`, "Pizza", "Tacos")
+
+	want := "This poll is binding: I understand this is binding\nUse !poll ack to acknowledge it and cast your vote for Tacos."
+	if reply := tp.run("room", "bob", "!poll vote 2"); reply != want {
+		t.Errorf("vote before acknowledging = %q, want %q", reply, want)
+	}
+	if got := tp.votes("room"); !reflect.DeepEqual(got, []int{0, 0}) {
+		t.Errorf("votes before acknowledging = %v, want the vote held", got)
+	}
+	if reply := tp.run("room", "bob", "!poll ack"); reply != "Acknowledged, vote recorded for Tacos" {
+		t.Errorf("ack = %q", reply)
+	}
+	if got := tp.votes("room"); !reflect.DeepEqual(got, []int{0, 1}) {
+		t.Errorf("votes after acknowledging = %v, want the held vote cast", got)
+	}
+
+	if reply := tp.run("room", "carol", "!poll ack"); reply != "Acknowledged, your votes will count." {
+		t.Errorf("ack without a held vote = %q", reply)
+	}
+	if err := tp.Vote("room", "carol", 1); err != nil {
+		t.Errorf("Vote after acknowledging failed: %v", err)
+	}
+	if err := tp.Vote("room", "dave", 1); !errors.Is(err, ErrAckRequired) {
+		t.Errorf("Vote without acknowledging = %v, want ErrAckRequired", err)
+	}
+	if got := tp.votes("room"); !reflect.DeepEqual(got, []int{1, 1}) {
+		t.Errorf("votes = %v", got)
+	}
+}
+
+func TestAckWithoutTerms(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.start("room", "", "Pizza", "Tacos")
+	if reply := tp.run("room", "bob", "!poll ack"); reply != "The poll has no terms to acknowledge." {
+		t.Errorf("ack = %q", reply)
+	}
+}
@@ -0,0 +1,81 @@
+package poll
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// isAdmin reports whether the user administers polls in the room, that is
+// whether the user is listed in the room's comma-separated admins pref.
+func (pl *Poller) isAdmin(roomId, userId string) bool {
+	for _, admin := range strings.Split(pl.pref(roomId, "admins", ""), ",") {
+		if admin = normalizeUser(admin); admin != "" && admin == userId {
+			return true
+		}
+	}
+	return false
+}
+
+// canManage reports whether the user may manage the poll, that is whether
+// the user created it or administers polls in the room.
+func (pl *Poller) canManage(roomId, userId string, poll *pollEntry) bool {
+	return userId == poll.Creator || pl.isAdmin(roomId, userId)
+}
+
+// refusal returns why the user may not run the command in the room, or an
+// empty string if the user may.
+func (pl *Poller) refusal(roomId, userId, command string) string {
+	if !adminCommands[command] && pl.pref(roomId, "disabled", "false") == "true" {
+		return "Polls are disabled in this room."
+	}
+	if !pl.canRun(roomId, userId, command) {
+		return fmt.Sprintf("You are not allowed to run !poll %s in this room.", command)
+	}
+	return ""
+}
+
+// canRun reports whether the user may run the command in the room. The
+// room's acl.<command> pref, e.g. acl.end, restricts the command to the
+// comma-separated users it lists, on top of the checks of the command
+// itself. Commands without an ACL are open to everybody.
+func (pl *Poller) canRun(roomId, userId, command string) bool {
+	acl := pl.pref(roomId, "acl."+command, "")
+	if strings.TrimSpace(acl) == "" {
+		return true
+	}
+	for _, user := range strings.Split(acl, ",") {
+		if user = normalizeUser(user); user != "" && user == userId {
+			return true
+		}
+	}
+	return false
+}
+
+// adminCommands are the commands restricted to admins, which still work in
+// rooms where polls are disabled.
+var adminCommands = map[string]bool{
+	"enable":         true,
+	"disable":        true,
+	"metrics":        true,
+	"debug":          true,
+	"selftest":       true,
+	"clear-inactive": true,
+	"confirm":        true,
+}
+
+// pollEnable enables or disables polls in the room through its disabled
+// pref.
+func (pl *Poller) pollEnable(roomId, userId string, enable bool) string {
+	if !pl.isAdmin(roomId, userId) {
+		return "Only poll admins can enable or disable polls."
+	}
+	if err := setPref(pl.name, roomId, "disabled", strconv.FormatBool(!enable)); err != nil {
+		pl.log().Warn("setting the disabled pref failed", "room", roomId, "err", err)
+		return "Could not change the setting, please try again later."
+	}
+	if enable {
+		return "Polls are enabled in this room."
+	}
+	return "Polls are disabled in this room."
+}
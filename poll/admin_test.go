@@ -0,0 +1,47 @@
+package poll
+
+import "testing"
+
+func TestACLDeniesCreator(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.prefs["room/acl.end"] = "carol, @dave"
+	tp.start("room", "", "Pizza", "Tacos")
+
+	if reply := tp.run("room", "alice", "!poll end"); reply != "You are not allowed to run !poll end in this room." {
+		t.Errorf("end of the creator reply = %q, want it denied", reply)
+	}
+	if !tp.hasPoll("room") {
+		t.Fatal("the denied end ended the poll")
+	}
+	if reply := tp.run("room", "alice", "!poll show"); reply == "You are not allowed to run !poll show in this room." {
+		t.Errorf("show without an ACL reply = %q, want it open", reply)
+	}
+	tp.run("room", "dave", "!poll end")
+	if tp.hasPoll("room") {
+		t.Error("end of a listed user didn't end the poll")
+	}
+}
+
+func TestDisable(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.prefs["room/admins"] = "root"
+
+	if reply := tp.run("room", "bob", "!poll disable"); reply != "Only poll admins can enable or disable polls." {
+		t.Errorf("disable of bob = %q, want it refused", reply)
+	}
+	tp.run("room", "root", "!poll disable")
+	if reply := tp.run("room", "alice", "!poll new Lunch?"); reply != "Polls are disabled in this room." {
+		t.Errorf("new in a disabled room = %q", reply)
+	}
+	if tp.hasPoll("room") {
+		t.Fatal("new created a poll in a disabled room")
+	}
+
+	if reply := tp.run("room", "root", "!poll enable"); reply != "Polls are enabled in this room." {
+		t.Errorf("enable = %q", reply)
+	}
+	tp.run("room", "alice", "!poll new Lunch?")
+	if !tp.hasPoll("room") {
+		t.Error("new didn't create a poll once re-enabled")
+	}
+}
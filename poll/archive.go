@@ -0,0 +1,228 @@
+package poll
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxClosedPolls is the number of ended polls retained, across rooms.
+const maxClosedPolls = 1000
+
+// closedPoll is a poll retained after it ended.
+type closedPoll struct {
+	RoomId   string
+	Poll     pollEntry
+	ClosedAt time.Time
+}
+
+// retainPoll keeps the ended poll, dropping the oldest ones past
+// maxClosedPolls. It must be called with the mutex held.
+func (pl *Poller) retainPoll(roomId string, poll *pollEntry) {
+	pl.closedPolls = append(pl.closedPolls, closedPoll{RoomId: roomId, Poll: *poll, ClosedAt: now()})
+	if len(pl.closedPolls) > maxClosedPolls {
+		pl.closedPolls = pl.closedPolls[len(pl.closedPolls)-maxClosedPolls:]
+	}
+}
+
+// Winner describes the most voted options of the poll, e.g. "Pizza (4 votes)".
+func (p pollEntry) Winner() string {
+	leaders := p.leaders()
+	if len(leaders) == 0 {
+		return "no votes"
+	}
+	most := formatCount(p.Options[leaders[0]].Votes)
+	if len(leaders) > 1 {
+		winners := make([]string, 0, len(leaders))
+		for _, k := range leaders {
+			winners = append(winners, p.Options[k].Text)
+		}
+		return fmt.Sprintf("tie between %s (%s votes each)", strings.Join(winners, ", "), most)
+	}
+	return fmt.Sprintf("%s (%s votes)", p.Options[leaders[0]].Text, most)
+}
+
+// DailyDigest summarizes the polls closed in the last 24 hours across rooms
+// along with their winners.
+func DailyDigest() string {
+	return defaultPoller.DailyDigest()
+}
+
+// DailyDigest summarizes the polls of the instance closed in the last 24
+// hours, see DailyDigest.
+func (pl *Poller) DailyDigest() string {
+	pl.mutex.RLock()
+	defer pl.mutex.RUnlock()
+
+	since := now().Add(-24 * time.Hour)
+	var lines []string
+	for _, c := range pl.closedPolls {
+		if c.ClosedAt.After(since) {
+			lines = append(lines, fmt.Sprintf(" %s: %s — %s", c.RoomId, c.Poll.Title, c.Poll.Winner()))
+		}
+	}
+	if len(lines) == 0 {
+		return "No polls closed in the last 24 hours."
+	}
+	return fmt.Sprintf("Polls closed in the last 24 hours:\n%s", strings.Join(lines, "\n"))
+}
+
+// archivePrefix is the storage key prefix of the archived polls.
+const archivePrefix = "archive/"
+
+// archiveKey returns the storage key of the poll archived by the room with
+// the id. The keys are scoped to the room, so that a room can only read back
+// the polls it archived.
+func archiveKey(roomId, id string) string {
+	return archivePrefix + roomId + "/" + id
+}
+
+// archiveRecord is the durable record of an archived poll.
+type archiveRecord struct {
+	Id          string          `json:"id"`
+	Code        string          `json:"code,omitempty"`
+	RoomId      string          `json:"room_id"`
+	Title       string          `json:"title"`
+	Description string          `json:"description,omitempty"`
+	Options     []archiveOption `json:"options"`
+	ClosedAt    time.Time       `json:"closed_at"`
+}
+
+type archiveOption struct {
+	Text  string `json:"text"`
+	Votes int    `json:"votes"`
+}
+
+func (r archiveRecord) poll() pollEntry {
+	poll := pollEntry{Code: r.Code, Title: r.Title, Description: r.Description}
+	for _, o := range r.Options {
+		poll.Options = append(poll.Options, pollOption{Text: o.Text, Votes: o.Votes})
+	}
+	return poll
+}
+
+// newArchiveRecord copies the final results of the poll of the room to
+// archive. It must be called with the mutex held, possibly for reading.
+func newArchiveRecord(roomId string, poll *pollEntry) archiveRecord {
+	record := archiveRecord{
+		Code:        poll.Code,
+		RoomId:      roomId,
+		Title:       poll.Title,
+		Description: poll.Description,
+		ClosedAt:    now(),
+	}
+	for _, o := range poll.Options {
+		record.Options = append(record.Options, archiveOption{Text: o.Text, Votes: o.Votes})
+	}
+	return record
+}
+
+// saveArchive writes the record to the storage and returns the id it can be
+// retrieved with, the code of the poll. A code already archived by the room,
+// as the codes are only unique among the polls in memory, gets a suffix
+// such as "a3-2". The storage may be slow, so it must be called without the
+// mutex held.
+func (pl *Poller) saveArchive(record archiveRecord) (string, error) {
+	s := currentStorage()
+	base := record.Code
+	if base == "" {
+		// polls loaded from dumps of older versions have no code
+		base = strconv.FormatInt(record.ClosedAt.UnixNano(), 36)
+	}
+	id := base
+	for n := 2; ; n++ {
+		if _, err := s.Get(archiveKey(record.RoomId, id)); errors.Is(err, ErrNotFound) {
+			break
+		} else if err != nil {
+			return "", err
+		}
+		id = fmt.Sprintf("%s-%d", base, n)
+	}
+
+	record.Id = id
+	data, err := json.Marshal(record)
+	if err != nil {
+		return "", err
+	}
+	if err := s.Put(archiveKey(record.RoomId, id), data); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// loadArchive reads a poll archived by the room from the storage.
+func (pl *Poller) loadArchive(roomId, id string) (archiveRecord, error) {
+	var record archiveRecord
+	data, err := currentStorage().Get(archiveKey(roomId, id))
+	if err != nil {
+		return record, err
+	}
+	err = json.Unmarshal(data, &record)
+	return record, err
+}
+
+// pollArchive ends the poll of the room and archives its final results. The
+// storage is written once the mutex is released, so the poll has ended
+// even when archiving it fails.
+func (pl *Poller) pollArchive(roomId, from string) string {
+	record, results, ok := pl.endArchived(roomId, from)
+	if !ok {
+		return results
+	}
+	id, err := pl.saveArchive(record)
+	if err != nil {
+		pl.log().Warn("archiving the poll failed", "room", roomId, "err", err)
+		return fmt.Sprintf("%s\nThe poll ended but could not be archived: %v", results, err)
+	}
+	return fmt.Sprintf("%s\nArchived as %s, see it with !poll archived %s", results, id, id)
+}
+
+// endArchived ends the poll of the room and returns the record to archive
+// along with its final results, or false and why the poll cannot end.
+func (pl *Poller) endArchived(roomId, from string) (archiveRecord, string, bool) {
+	pl.mutex.Lock()
+	defer pl.unlock()
+
+	poll, ok := pl.polls[roomId]
+	if !ok {
+		return archiveRecord{}, "There is no poll.", false
+	}
+	if !poll.IsActive {
+		return archiveRecord{}, "There is no active poll.", false
+	}
+	record := newArchiveRecord(roomId, poll)
+	return record, pl.endPoll(roomId, poll, from), true
+}
+
+// findEnded returns the poll of the room that ended with the code, kept in
+// memory or archived with it as its id, along with when it closed.
+func (pl *Poller) findEnded(roomId, code string) (pollEntry, time.Time, error) {
+	pl.mutex.RLock()
+	for k := len(pl.closedPolls) - 1; k >= 0; k-- {
+		if c := pl.closedPolls[k]; c.RoomId == roomId && c.Poll.Code == code {
+			pl.mutex.RUnlock()
+			return c.Poll, c.ClosedAt, nil
+		}
+	}
+	pl.mutex.RUnlock()
+
+	record, err := pl.loadArchive(roomId, code)
+	if err != nil {
+		return pollEntry{}, time.Time{}, err
+	}
+	return record.poll(), record.ClosedAt, nil
+}
+
+func (pl *Poller) pollArchived(roomId, id string) string {
+	record, err := pl.loadArchive(roomId, id)
+	if errors.Is(err, ErrNotFound) {
+		return fmt.Sprintf("There is no archived poll %s.", id)
+	}
+	if err != nil {
+		return fmt.Sprintf("Could not read the archived poll %s: %v", id, err)
+	}
+	return fmt.Sprintf("Poll archived on %s:\n%s", record.ClosedAt.Format(time.RFC3339), record.poll().Details())
+}
@@ -0,0 +1,124 @@
+package poll
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDailyDigest(t *testing.T) {
+	tp := newTestPoller(t)
+	if got := tp.DailyDigest(); got != "No polls closed in the last 24 hours." {
+		t.Errorf("digest without polls = %q", got)
+	}
+
+	tp.start("old", "", "Pizza", "Tacos")
+	tp.castVotes("old", 1)
+	tp.run("old", "alice", "!poll end")
+	tp.clock.Advance(46 * time.Hour)
+	tp.start("room", "", "Pizza", "Tacos")
+	tp.castVotes("room", 2, 2, 1)
+	tp.run("room", "alice", "!poll end")
+	tp.clock.Advance(2 * time.Hour)
+
+	if got, want := tp.DailyDigest(), "Polls closed in the last 24 hours:\n room: Lunch? — Tacos (2 votes)"; got != want {
+		t.Errorf("digest = %q, want %q", got, want)
+	}
+}
+
+func TestArchive(t *testing.T) {
+	tp := newTestPoller(t)
+	s := newMemStorage()
+	SetStorage(s)
+	t.Cleanup(func() { SetStorage(nil) })
+	tp.start("room", "", "Pizza", "Tacos")
+	tp.castVotes("room", 2, 2, 1)
+
+	reply := tp.run("room", "alice", "!poll archive")
+	_, id, ok := strings.Cut(reply, "\nArchived as ")
+	if !strings.HasPrefix(reply, "Poll finished, final results:") || !ok {
+		t.Fatalf("archive reply = %q, want the results and the id", reply)
+	}
+	id, _, _ = strings.Cut(id, ",")
+	if tp.hasPoll("room") {
+		t.Error("the poll is still running once archived")
+	}
+
+	data, err := s.Get(archiveKey("room", id))
+	if err != nil {
+		t.Fatalf("reading the archived poll %s from the storage failed: %v", id, err)
+	}
+	var record archiveRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		t.Fatalf("decoding the archived poll failed: %v", err)
+	}
+	if want := []archiveOption{{"Pizza", 1}, {"Tacos", 2}}; record.Title != "Lunch?" || !reflect.DeepEqual(record.Options, want) {
+		t.Errorf("archived poll = %+v, want Lunch? with the options %+v", record, want)
+	}
+
+	want := "Poll archived on 2024-03-04T10:00:00Z:\nLunch?\n 1. Pizza (1 votes)\n 2. Tacos (2 votes)"
+	if reply := tp.run("room", "bob", "!poll archived "+id); reply != want {
+		t.Errorf("archived = %q, want %q", reply, want)
+	}
+	if reply := tp.run("other", "bob", "!poll archived "+id); reply != "There is no archived poll "+id+"." {
+		t.Errorf("archived from another room = %q, want it not found", reply)
+	}
+}
+
+func TestArchiveFails(t *testing.T) {
+	tp := newTestPoller(t)
+	SetStorage(failingStorage{newMemStorage(), errors.New("disk full")})
+	t.Cleanup(func() { SetStorage(nil) })
+	tp.start("room", "", "Pizza", "Tacos")
+
+	reply := tp.run("room", "alice", "!poll archive")
+	if !strings.HasPrefix(reply, "Poll finished, final results:") || !strings.HasSuffix(reply, "\nThe poll ended but could not be archived: disk full") {
+		t.Errorf("archive reply = %q, want the results and the failure", reply)
+	}
+	if tp.hasPoll("room") {
+		t.Error("the poll is still running")
+	}
+}
+
+// archive archives the poll of the room and returns the id it was archived
+// as.
+func (tp *testPoller) archive(roomId string) string {
+	tp.t.Helper()
+	reply := tp.run(roomId, "alice", "!poll archive")
+	_, id, ok := strings.Cut(reply, "\nArchived as ")
+	if !ok {
+		tp.t.Fatalf("archive reply = %q, want the id", reply)
+	}
+	id, _, _ = strings.Cut(id, ",")
+	return id
+}
+
+func TestArchiveByCode(t *testing.T) {
+	tp := newTestPoller(t)
+	t.Cleanup(func() { SetStorage(nil) })
+	SetStorage(newMemStorage())
+	tp.start("room", "", "Pizza", "Tacos")
+	tp.mutex.RLock()
+	code := tp.polls["room"].Code
+	tp.mutex.RUnlock()
+
+	if id := tp.archive("room"); id != code {
+		t.Errorf("archived as %s, want the code %s", id, code)
+	}
+	if reply := tp.run("room", "bob", "!poll archived "+code); !strings.HasPrefix(reply, "Poll archived on ") {
+		t.Errorf("archived by code = %q", reply)
+	}
+
+	// the codes are only unique among the polls in memory
+	tp.closedPolls = nil
+	tp.start("room", "", "Soup", "Salad")
+	tp.mutex.Lock()
+	tp.polls["room"].Code = code
+	tp.mutex.Unlock()
+	if id := tp.archive("room"); id != code+"-2" {
+		t.Errorf("archived as %s, want %s-2", id, code)
+	}
+}
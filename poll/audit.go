@@ -0,0 +1,24 @@
+package poll
+
+import (
+	"fmt"
+	"time"
+)
+
+// auditRecord is an entry of the audit log of a poll, recording who did what
+// on behalf of whom.
+type auditRecord struct {
+	Time   time.Time
+	Actor  string
+	Action string
+}
+
+// audit appends an entry to the audit log of the poll. It must be called
+// with the mutex held.
+func (p *pollEntry) audit(actor, format string, a ...interface{}) {
+	p.Audit = append(p.Audit, auditRecord{
+		Time:   now(),
+		Actor:  actor,
+		Action: fmt.Sprintf(format, a...),
+	})
+}
@@ -0,0 +1,46 @@
+package poll
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// boardClosed is the number of closed polls listed by !poll board, and
+// boardSince how recently they must have closed.
+const (
+	boardClosed = 10
+	boardSince  = 7 * 24 * time.Hour
+)
+
+// pollBoard lists the poll of the room followed by the polls closed in the
+// room recently, most recent first.
+func (pl *Poller) pollBoard(roomId string) string {
+	pl.mutex.RLock()
+	defer pl.mutex.RUnlock()
+
+	var lines []string
+	if poll, ok := pl.polls[roomId]; ok {
+		lines = append(lines, fmt.Sprintf(" [%s] %s — %s", poll.Code, poll.viewedBy("").Title, poll.Status()))
+	}
+
+	now := now()
+	since := now.Add(-boardSince)
+	closed := 0
+	for k := len(pl.closedPolls) - 1; k >= 0 && closed < boardClosed; k-- {
+		c := pl.closedPolls[k]
+		if c.RoomId != roomId {
+			continue
+		}
+		if !c.ClosedAt.After(since) {
+			break
+		}
+		lines = append(lines, fmt.Sprintf(" [%s] %s — closed %s ago, %s", c.Poll.Code, c.Poll.Title, formatDuration(now.Sub(c.ClosedAt)), c.Poll.Winner()))
+		closed++
+	}
+
+	if len(lines) == 0 {
+		return "There are no polls in this room."
+	}
+	return fmt.Sprintf("Polls in this room:\n%s", strings.Join(lines, "\n"))
+}
@@ -0,0 +1,33 @@
+package poll
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBoard(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.start("room", "", "Breakfast", "Brunch")
+	tp.run("room", "alice", "!poll end")
+	tp.clock.Advance(8 * 24 * time.Hour)
+	tp.start("room", "", "Pizza", "Tacos")
+	tp.castVotes("room", 2)
+	tp.run("room", "alice", "!poll end")
+	tp.start("other", "", "Soup", "Salad")
+	tp.run("other", "alice", "!poll end")
+	tp.clock.Advance(time.Hour)
+	tp.start("room", "", "Coffee", "Tea")
+
+	reply := tp.run("room", "bob", "!poll board")
+	lines := strings.Split(reply, "\n")
+	if len(lines) != 3 || lines[0] != "Polls in this room:" {
+		t.Fatalf("board = %q, want the active poll and the recently-closed one", reply)
+	}
+	if !strings.Contains(lines[1], "Lunch? — ") || strings.Contains(lines[1], "closed") {
+		t.Errorf("first line = %q, want the active poll", lines[1])
+	}
+	if !strings.Contains(lines[2], "closed 1h0m0s ago") || !strings.Contains(lines[2], "Tacos") {
+		t.Errorf("second line = %q, want the poll closed an hour ago won by Tacos", lines[2])
+	}
+}
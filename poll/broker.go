@@ -0,0 +1,200 @@
+package poll
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/netflix/hal-9001/hal"
+)
+
+// brokerMaxLengths are the default maximum reply lengths by broker type.
+var brokerMaxLengths = map[string]int{
+	"slack":   4000,
+	"discord": 2000,
+}
+
+// truncatedMarker ends the replies cut down to the maximum reply length.
+const truncatedMarker = "(truncated)"
+
+// brokerType returns the lower-cased name of the broker of the event.
+func brokerType(evt hal.Evt) string {
+	if evt.Broker == nil {
+		return ""
+	}
+	return strings.ToLower(evt.Broker.Name())
+}
+
+// maxReplyLength returns the maximum length of a reply in bytes, as set by
+// the room's reply.maxlength pref or else by the broker type. Zero means no
+// limit.
+func (pl *Poller) maxReplyLength(evt hal.Evt) int {
+	def := strconv.Itoa(brokerMaxLengths[brokerType(evt)])
+	limit, err := strconv.Atoi(pl.pref(evt.RoomId, "reply.maxlength", def))
+	if err != nil || limit < 0 {
+		return 0
+	}
+	return limit
+}
+
+// truncate cuts msg down to at most limit bytes, at the end of a line when
+// possible, and appends truncatedMarker.
+func truncate(msg string, limit int) string {
+	return truncateMarked(msg, limit, truncatedMarker)
+}
+
+// truncateMarked is like truncate, appending marker, e.g. the translation of
+// truncatedMarker.
+func truncateMarked(msg string, limit int, marker string) string {
+	if limit <= 0 || len(msg) <= limit {
+		return msg
+	}
+	if limit <= len(marker) {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(marker[cut]) {
+			cut--
+		}
+		return marker[:cut]
+	}
+	cut := limit - len(marker) - 1
+	for cut > 0 && !utf8.RuneStart(msg[cut]) {
+		cut--
+	}
+	if i := strings.LastIndex(msg[:cut], "\n"); i > 0 {
+		cut = i
+	}
+	return msg[:cut] + "\n" + marker
+}
+
+// ephemeralSender is implemented by brokers that can send a message only
+// visible to the user of the event, such as Slack's ephemeral messages.
+type ephemeralSender interface {
+	SendEphemeral(evt hal.Evt) error
+}
+
+// privateFailed is replied to the room when a private reply could be sent
+// neither as an ephemeral message nor as a direct message.
+const privateFailed = "Could not reply to you privately, please allow direct messages and retry."
+
+// replyPrivately replies to the user alone, as an ephemeral message when the
+// broker supports it and as a direct message otherwise. The message may not
+// be for everybody's eyes, so it is never posted to the room: when both
+// fail, the user is only told so in the room.
+func (pl *Poller) replyPrivately(evt hal.Evt, msg string) {
+	msg = truncate(msg, pl.maxReplyLength(evt))
+	if sender, ok := evt.Broker.(ephemeralSender); ok {
+		out := evt
+		out.Body = msg
+		err := sender.SendEphemeral(out)
+		if err == nil {
+			return
+		}
+		pl.log().Warn("ephemeral reply failed", "room", evt.RoomId, "err", err)
+	}
+	if err := trySendDM(evt, msg); err != nil {
+		pl.log().Warn("direct message failed", "user", evt.UserId, "err", err)
+		pl.reply(evt, privateFailed)
+	}
+}
+
+// replyVote replies to a vote as an ephemeral message when the broker
+// supports it, sparing the room a message per vote, and to the room
+// otherwise, as the reply is nothing the room may not see.
+func (pl *Poller) replyVote(evt hal.Evt, msg string) {
+	if sender, ok := evt.Broker.(ephemeralSender); ok {
+		out := evt
+		out.Body = truncate(msg, pl.maxReplyLength(evt))
+		err := sender.SendEphemeral(out)
+		if err == nil {
+			return
+		}
+		pl.log().Warn("ephemeral reply failed", "room", evt.RoomId, "err", err)
+	}
+	pl.reply(evt, msg)
+}
+
+// fileSender is implemented by brokers that can upload a file to the room of
+// the event.
+type fileSender interface {
+	SendFile(evt hal.Evt, name string, data []byte) error
+}
+
+// resultsFile is the name of the file the results too long for a reply are
+// attached as.
+const resultsFile = "poll-results.txt"
+
+// replyResults replies with the results of a poll. Results longer than the
+// maximum reply length are attached as a file when the broker supports it,
+// with their first line as the reply, and truncated otherwise.
+func (pl *Poller) replyResults(evt hal.Evt, msg string) {
+	limit := pl.maxReplyLength(evt)
+	if sender, ok := evt.Broker.(fileSender); ok && limit > 0 && len(msg) > limit {
+		err := sender.SendFile(evt, resultsFile, []byte(msg))
+		if err == nil {
+			first, _, _ := strings.Cut(msg, "\n")
+			pl.reply(evt, fmt.Sprintf("%s\nThe full results are attached as %s.", first, resultsFile))
+			return
+		}
+		pl.log().Warn("attaching the results failed", "room", evt.RoomId, "err", err)
+	}
+	pl.reply(evt, msg)
+}
+
+// pinner is implemented by brokers that can post a message to the room of
+// the event and pin it.
+type pinner interface {
+	SendPinned(evt hal.Evt) error
+}
+
+// replyPinned replies with the results of a poll and pins them when the
+// broker supports it, and replies as replyResults otherwise.
+func (pl *Poller) replyPinned(evt hal.Evt, msg string) {
+	if p, ok := evt.Broker.(pinner); ok {
+		out := evt
+		out.Body = truncate(msg, pl.maxReplyLength(evt))
+		err := p.SendPinned(out)
+		if err == nil {
+			return
+		}
+		pl.log().Warn("pinning the results failed", "room", evt.RoomId, "err", err)
+	}
+	pl.replyResults(evt, msg)
+}
+
+// reply replies to the event without letting a failing broker bring the
+// plugin down. State changed by the command stays committed, so when the
+// reply fails the user is told through a direct message instead, if possible.
+func (pl *Poller) reply(evt hal.Evt, msg string) {
+	msg = truncate(msg, pl.maxReplyLength(evt))
+	if err := tryReply(evt, msg); err != nil {
+		pl.log().Warn("reply failed", "room", evt.RoomId, "err", err)
+		if err := trySendDM(evt, msg); err != nil {
+			pl.log().Warn("direct message failed", "user", evt.UserId, "err", err)
+		}
+	}
+}
+
+func tryReply(evt hal.Evt, msg string) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	evt.Reply(msg)
+	return nil
+}
+
+func trySendDM(evt hal.Evt, msg string) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	if evt.Broker == nil {
+		return errors.New("no broker")
+	}
+	sendDM(evt, msg)
+	return nil
+}
@@ -0,0 +1,236 @@
+package poll
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/netflix/hal-9001/hal"
+)
+
+// ephemeralBroker is a fakeBroker whose ephemeral messages fail with err,
+// or are recorded when err is nil.
+type ephemeralBroker struct {
+	*fakeBroker
+	err error
+}
+
+func (b ephemeralBroker) SendEphemeral(evt hal.Evt) error {
+	if b.err != nil {
+		return b.err
+	}
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.sent = append(b.sent, sent{RoomId: evt.RoomId, UserId: evt.UserId, Ephemeral: true, Body: evt.Body})
+	return nil
+}
+
+func TestVoteConfirmedEphemerally(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.via = ephemeralBroker{tp.broker, nil}
+	tp.start("room", "", "Pizza", "Tacos")
+
+	n := tp.broker.count()
+	tp.run("room", "bob", "!poll vote 1")
+	want := []sent{{RoomId: "room", UserId: "bob", Ephemeral: true, Body: "Poll:\nLunch?\n 1. Pizza (1 votes)\n 2. Tacos (0 votes)"}}
+	if got := tp.broker.since(n); !reflect.DeepEqual(got, want) {
+		t.Errorf("messages = %+v, want %+v", got, want)
+	}
+}
+
+// failingBroker is a fakeBroker failing to send to the rooms.
+type failingBroker struct {
+	*fakeBroker
+}
+
+func (b failingBroker) Send(evt hal.Evt) int { panic("connection reset") }
+
+func TestReplyFails(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.run("room", "alice", "!poll new Lunch?")
+	tp.via = failingBroker{tp.broker}
+
+	n := tp.broker.count()
+	tp.run("room", "alice", "!poll option Pizza")
+	want := []sent{{RoomId: "room", UserId: "alice", DM: true, Body: "Added as option 1: Pizza"}}
+	if msgs := tp.broker.since(n); !reflect.DeepEqual(msgs, want) {
+		t.Errorf("messages = %+v, want the reply as a direct message %+v", msgs, want)
+	}
+	if got := tp.votes("room"); len(got) != 1 {
+		t.Errorf("options = %d, want the option added", len(got))
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	for _, tt := range []struct {
+		msg   string
+		limit int
+		want  string
+	}{
+		{"Poll:\nLunch?", 0, "Poll:\nLunch?"},
+		{"Poll:\nLunch?", 12, "Poll:\nLunch?"},
+		{"Poll:\nLunch?\n 1. Pizza (1 votes)", 24, "Poll:\n(truncated)"},
+		{"Poll: Lunch?", 5, "(trun"},
+		{"Café au lait, please", 16, "Caf\n(truncated)"}, // not within é
+	} {
+		if got := truncate(tt.msg, tt.limit); got != tt.want {
+			t.Errorf("truncate(%q, %d) = %q, want %q", tt.msg, tt.limit, got, tt.want)
+		}
+	}
+}
+
+func TestResultTruncated(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.prefs["room/reply.maxlength"] = "120"
+	tp.start("room", "", strings.Repeat("Pizza ", 10), strings.Repeat("Tacos ", 10), strings.Repeat("Sushi ", 10))
+
+	reply := tp.run("room", "bob", "!poll show")
+	if len(reply) > 120 || !strings.HasSuffix(reply, "\n"+truncatedMarker) {
+		t.Errorf("reply of %d bytes = %q, want at most 120 bytes ending with %s", len(reply), reply, truncatedMarker)
+	}
+	if !strings.HasPrefix(reply, "Poll:\nLunch?\n 1. Pizza") {
+		t.Errorf("reply = %q, want the start of the results kept", reply)
+	}
+}
+
+// fileBroker is a fakeBroker recording the files attached to the rooms.
+type fileBroker struct {
+	*fakeBroker
+	files map[string][]byte
+}
+
+func (b fileBroker) SendFile(evt hal.Evt, name string, data []byte) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.files[name] = data
+	return nil
+}
+
+func TestResultsAttached(t *testing.T) {
+	tp := newTestPoller(t)
+	b := fileBroker{tp.broker, make(map[string][]byte)}
+	tp.via = b
+	tp.prefs["room/reply.maxlength"] = "100"
+	tp.start("room", "", "Pizza", "Tacos")
+
+	if reply := tp.run("room", "alice", "!poll end"); reply != "Poll finished, final results:\nLunch?\n 1. Pizza (0 votes)\n 2. Tacos (0 votes)" {
+		t.Errorf("end below the limit = %q, want the results inline", reply)
+	}
+	if len(b.files) > 0 {
+		t.Errorf("files = %v, want none below the limit", b.files)
+	}
+
+	tp.start("room", "", strings.Repeat("Pizza ", 10), strings.Repeat("Tacos ", 10))
+	reply := tp.run("room", "alice", "!poll end")
+	if reply != "Poll finished, final results:\nThe full results are attached as "+resultsFile+"." {
+		t.Errorf("end above the limit = %q, want the results attached", reply)
+	}
+	if data := string(b.files[resultsFile]); !strings.Contains(data, strings.Repeat("Tacos ", 10)) {
+		t.Errorf("attached %q, want the full results", data)
+	}
+}
+
+// pinnedBroker is a fakeBroker recording the messages it posts and pins.
+type pinnedBroker struct {
+	*fakeBroker
+	pinned *[]string
+}
+
+func (b pinnedBroker) SendPinned(evt hal.Evt) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	*b.pinned = append(*b.pinned, evt.Body)
+	return nil
+}
+
+func TestEndPinned(t *testing.T) {
+	tp := newTestPoller(t)
+	var pinned []string
+	tp.via = pinnedBroker{tp.broker, &pinned}
+	tp.start("room", "", "Pizza", "Tacos")
+	tp.castVotes("room", 2)
+
+	n := tp.broker.count()
+	tp.run("room", "alice", "!poll end -pin")
+	want := []string{"Poll finished, final results:\nLunch?\n 1. Pizza (0 votes)\n 2. Tacos (1 votes)"}
+	if !reflect.DeepEqual(pinned, want) {
+		t.Errorf("pinned = %q, want %q", pinned, want)
+	}
+	if msgs := tp.broker.since(n); len(msgs) > 0 {
+		t.Errorf("messages = %+v, want the results only pinned", msgs)
+	}
+}
+
+func TestEndPinnedUnsupported(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.start("room", "", "Pizza", "Tacos")
+
+	if reply := tp.run("room", "alice", "!poll end -pin"); reply != "Poll finished, final results:\nLunch?\n 1. Pizza (0 votes)\n 2. Tacos (0 votes)" {
+		t.Errorf("end = %q, want the results replied", reply)
+	}
+}
+
+func TestReplyPrivately(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error // of the ephemeral message
+		dmFails  bool
+		wantSent []sent
+	}{
+		{
+			name:     "ephemeral",
+			wantSent: []sent{{RoomId: "room", UserId: "bob", Ephemeral: true, Body: "secret"}},
+		},
+		{
+			name:     "direct message once ephemeral fails",
+			err:      errors.New("not in the channel"),
+			wantSent: []sent{{RoomId: "room", UserId: "bob", DM: true, Body: "secret"}},
+		},
+		{
+			name:     "refusal once both fail",
+			err:      errors.New("not in the channel"),
+			dmFails:  true,
+			wantSent: []sent{{RoomId: "room", UserId: "bob", Body: privateFailed}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tp := newTestPoller(t)
+			if tt.dmFails {
+				old := sendDM
+				sendDM = func(evt hal.Evt, msg string) { panic("direct messages are disabled") }
+				t.Cleanup(func() { sendDM = old })
+			}
+			evt := hal.Evt{RoomId: "room", UserId: "bob", Broker: ephemeralBroker{tp.broker, tt.err}}
+
+			tp.replyPrivately(evt, "secret")
+			if got := tp.broker.since(0); !reflect.DeepEqual(got, tt.wantSent) {
+				t.Errorf("messages = %+v, want %+v", got, tt.wantSent)
+			}
+		})
+	}
+}
+
+func TestVoteRepliedToRoom(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		via  func(*fakeBroker) hal.Broker
+	}{
+		{"without ephemeral messages", func(b *fakeBroker) hal.Broker { return b }},
+		{"once the ephemeral message fails", func(b *fakeBroker) hal.Broker { return ephemeralBroker{b, errors.New("not in the channel")} }},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			tp := newTestPoller(t)
+			tp.via = tt.via(tp.broker)
+			tp.start("room", "", "Pizza", "Tacos")
+
+			n := tp.broker.count()
+			tp.run("room", "bob", "!poll vote 1")
+			want := []sent{{RoomId: "room", UserId: "bob", Body: "Poll:\nLunch?\n 1. Pizza (1 votes)\n 2. Tacos (0 votes)"}}
+			if got := tp.broker.since(n); !reflect.DeepEqual(got, want) {
+				t.Errorf("messages = %+v, want the vote replied to the room %+v", got, want)
+			}
+		})
+	}
+}
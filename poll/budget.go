@@ -0,0 +1,101 @@
+package poll
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// parseAllocation parses allocations such as "1=40 2=60" into the points
+// given to each option index.
+func parseAllocation(args []string) (map[int]int, error) {
+	points := make(map[int]int, len(args))
+	for _, arg := range args {
+		i := strings.Index(arg, "=")
+		if i < 0 {
+			return nil, fmt.Errorf("Please allocate points as <index>=<points>, not %s.", arg)
+		}
+		index, err1 := strconv.Atoi(arg[:i])
+		n, err2 := strconv.Atoi(arg[i+1:])
+		if err1 != nil || err2 != nil || n <= 0 {
+			return nil, fmt.Errorf("Please allocate a positive number of points to the numerical index of an option, not %s.", arg)
+		}
+		points[index] += n
+	}
+	return points, nil
+}
+
+// pollAllocate records the points the user allocates across the options of
+// a poll created with -budget. Each user allocates once, at most the budget
+// of the poll in total.
+func (pl *Poller) pollAllocate(roomId, userId, userName string, args []string) string {
+	points, err := parseAllocation(args)
+	if err != nil {
+		return err.Error()
+	}
+
+	pl.mutex.Lock()
+	defer pl.unlock()
+
+	poll, total, err := pl.allocate(roomId, userId, userName, points)
+	if errors.Is(err, ErrCooldown) {
+		return ""
+	}
+	if err != nil {
+		return errorMessage(err)
+	}
+	return fmt.Sprintf("Allocated %d of your %d points.\n%s", total, poll.Budget, poll.viewedBy(userId).Result())
+}
+
+// allocate records the points the user allocates to each option index and
+// returns the total, going through the checks and announcements of a vote.
+// The points cannot be reallocated, even before the votes are locked. It
+// must be called with the mutex held.
+func (pl *Poller) allocate(roomId, userId, userName string, points map[int]int) (poll *pollEntry, total int, err error) {
+	defer func() { pl.logVote(roomId, userId, poll, 0, err) }()
+
+	poll, err = pl.activePoll(roomId)
+	if err != nil {
+		return nil, 0, err
+	}
+	if poll.Budget == 0 {
+		return nil, 0, ErrNotBudgetPoll
+	}
+	indices := make([]int, 0, len(points))
+	for index, n := range points {
+		indices = append(indices, index)
+		total += n
+	}
+	sort.Ints(indices)
+	now := now()
+	hasVoted, err := pl.checkVote(roomId, poll, userId, userName, now, indices...)
+	if err != nil {
+		return nil, 0, err
+	}
+	if hasVoted {
+		return nil, 0, ErrAlreadyVoted
+	}
+	if total > poll.Budget {
+		return nil, 0, fmt.Errorf("You allocated %d points, over the budget of %d.", total, poll.Budget)
+	}
+	if !poll.acked(userId) {
+		return nil, 0, ackError{terms: poll.Ack}
+	}
+
+	for _, index := range indices {
+		poll.Options[index-1].Votes += points[index]
+	}
+	poll.HasVoted = append(poll.HasVoted, userId)
+	pl.afterVote(roomId, poll, userId, now, indices...)
+	return poll, total, nil
+}
+
+// unit names what the tallies of the poll count, votes or allocated points.
+func (p pollEntry) unit() string {
+	if p.Budget > 0 {
+		return "points"
+	}
+	return "votes"
+}
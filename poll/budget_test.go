@@ -0,0 +1,67 @@
+package poll
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAllocate(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.start("room", "-budget=100", "Pizza", "Tacos")
+
+	if reply := tp.run("room", "bob", "!poll vote 1"); reply != "This poll allocates points, use !poll allocate <index>=<points>... to vote." {
+		t.Errorf("plain vote reply = %q", reply)
+	}
+	if reply := tp.run("room", "bob", "!poll allocate 1=70 2=40"); reply != "You allocated 110 points, over the budget of 100." {
+		t.Errorf("allocation over the budget reply = %q", reply)
+	}
+	if reply := tp.run("room", "bob", "!poll allocate 3=10"); reply != "Please choose a number between 1 to 2" {
+		t.Errorf("allocation out of range reply = %q", reply)
+	}
+	reply := tp.run("room", "bob", "!poll allocate 1=60 2=40")
+	if !strings.HasPrefix(reply, "Allocated 100 of your 100 points.") {
+		t.Errorf("allocation reply = %q", reply)
+	}
+	if !strings.Contains(reply, "Pizza (60 points)") || !strings.Contains(reply, "Tacos (40 points)") {
+		t.Errorf("allocation reply = %q, want the point totals", reply)
+	}
+	tp.clock.Advance(time.Minute)
+	if reply := tp.run("room", "bob", "!poll allocate 2=100"); reply != "You have already voted." {
+		t.Errorf("second allocation reply = %q", reply)
+	}
+	if got, want := tp.votes("room"), []int{60, 40}; !reflect.DeepEqual(got, want) {
+		t.Errorf("points = %v, want %v", got, want)
+	}
+}
+
+func TestAllocateSharesVoteChecks(t *testing.T) {
+	tp := newTestPoller(t)
+	t.Cleanup(func() {
+		validatorsMutex.Lock()
+		defer validatorsMutex.Unlock()
+		voteValidators = nil
+	})
+	RegisterVoteValidator(ClosedHours(10, 11))
+	tp.start("room", "-budget=10 -ack=binding -quorum=1", "Pizza", "Tacos")
+
+	if reply := tp.run("room", "bob", "!poll allocate 1=10"); reply != "Voting is closed from 10:00 to 11:00." {
+		t.Errorf("allocation in the closed hours reply = %q", reply)
+	}
+	tp.clock.Advance(time.Hour)
+	if reply := tp.run("room", "bob", "!poll allocate 1=10"); !strings.HasPrefix(reply, "This poll is binding: binding\n") {
+		t.Errorf("allocation before acknowledging reply = %q", reply)
+	}
+	tp.run("room", "bob", "!poll ack")
+	reply := tp.run("room", "bob", "!poll allocate 1=10")
+	if !strings.Contains(reply, "Quorum reached!") || !strings.Contains(reply, "Allocated 10 of your 10 points.") {
+		t.Errorf("allocation replies = %q, want the quorum and the allocation", reply)
+	}
+
+	tp.mutex.RLock()
+	defer tp.mutex.RUnlock()
+	if timeline := tp.polls["room"].Timeline; len(timeline) != 1 || timeline[0].Option != 0 {
+		t.Errorf("timeline = %+v, want the allocation", timeline)
+	}
+}
@@ -0,0 +1,81 @@
+package poll
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/netflix/hal-9001/hal"
+)
+
+// integrations are the broker features the plugin makes use of, each with
+// the type assertion telling whether a broker has it.
+var integrations = []struct {
+	name string
+	has  func(b hal.Broker) bool
+}{
+	{"blocks", func(b hal.Broker) bool {
+		_, ok := b.(blockSender)
+		return ok && strings.EqualFold(b.Name(), "slack")
+	}},
+	{"edit-message", func(b hal.Broker) bool { _, ok := b.(messageEditor); return ok }},
+	{"ephemeral", func(b hal.Broker) bool { _, ok := b.(ephemeralSender); return ok }},
+	{"files", func(b hal.Broker) bool { _, ok := b.(fileSender); return ok }},
+	{"members", func(b hal.Broker) bool { _, ok := b.(memberLister); return ok }},
+	{"pin", func(b hal.Broker) bool {
+		_, messages := b.(messagePinner)
+		_, results := b.(pinner)
+		return messages || results
+	}},
+	{"reactions", func(b hal.Broker) bool { _, ok := b.(messageSender); return ok }},
+	{"tenure", func(b hal.Broker) bool { _, ok := b.(TenureBroker); return ok }},
+}
+
+// Capabilities lists the features of the plugin, for tools driving it to
+// discover them: "command:<name>" for each command, "flag:<name>" for each
+// flag of !poll new, "storage:<name>" for the storage set, and "hooks:vote"
+// and "hooks:threshold" once vote validators or threshold hooks are
+// registered. See BrokerCapabilities for the broker features.
+func Capabilities() []string {
+	return BrokerCapabilities(nil)
+}
+
+// BrokerCapabilities is like Capabilities, adding "integration:<name>" for
+// each broker feature of the plugin the broker has.
+func BrokerCapabilities(b hal.Broker) []string {
+	var caps []string
+	for name := range commands {
+		caps = append(caps, "command:"+name)
+	}
+	for name := range newFlags {
+		caps = append(caps, "flag:"+name)
+	}
+	if b != nil {
+		for _, i := range integrations {
+			if i.has(b) {
+				caps = append(caps, "integration:"+i.name)
+			}
+		}
+	}
+
+	caps = append(caps, "storage:"+storageName(currentStorage()))
+	validatorsMutex.RLock()
+	if len(voteValidators) > 0 {
+		caps = append(caps, "hooks:vote")
+	}
+	validatorsMutex.RUnlock()
+	hooksMutex.RLock()
+	if len(thresholdHooks) > 0 {
+		caps = append(caps, "hooks:threshold")
+	}
+	hooksMutex.RUnlock()
+
+	sort.Strings(caps)
+	return caps
+}
+
+// isCommand reports whether the word is the name of a command of the
+// plugin, whatever its case.
+func isCommand(word string) bool {
+	_, ok := commands[strings.ToLower(word)]
+	return ok
+}
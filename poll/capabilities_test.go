@@ -0,0 +1,83 @@
+package poll
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+)
+
+// hasCapability reports whether caps lists the capability.
+func hasCapability(caps []string, capability string) bool {
+	for _, c := range caps {
+		if c == capability {
+			return true
+		}
+	}
+	return false
+}
+
+func TestCapabilities(t *testing.T) {
+	caps := Capabilities()
+	for _, want := range []string{"command:capabilities", "command:vote", "flag:pin", "flag:verbs", "storage:memory"} {
+		if !hasCapability(caps, want) {
+			t.Errorf("Capabilities() = %q, want %s", caps, want)
+		}
+	}
+	for _, c := range caps {
+		if strings.HasPrefix(c, "integration:") || strings.HasPrefix(c, "hooks:") {
+			t.Errorf("Capabilities() lists %s, with neither a broker nor hooks", c)
+		}
+	}
+
+	registerVoteValidator(t, func(roomId, userId string, index int) error { return nil })
+	if caps := Capabilities(); !hasCapability(caps, "hooks:vote") || hasCapability(caps, "hooks:threshold") {
+		t.Errorf("Capabilities() = %q, want hooks:vote alone", caps)
+	}
+}
+
+func TestCapabilitiesStorage(t *testing.T) {
+	t.Cleanup(func() { SetStorage(nil) })
+
+	// the wrapper hides the name of the memory storage
+	SetStorage(struct{ Storage }{newMemStorage()})
+	if caps := Capabilities(); !hasCapability(caps, "storage:custom") || hasCapability(caps, "storage:memory") {
+		t.Errorf("Capabilities() with a custom storage = %q, want storage:custom", caps)
+	}
+	SetStorage(nil)
+	if caps := Capabilities(); !hasCapability(caps, "storage:memory") {
+		t.Errorf("Capabilities() by default = %q, want storage:memory", caps)
+	}
+}
+
+func TestCapabilitiesCommand(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.via = memberBroker{tp.broker, nil}
+
+	caps := strings.Split(tp.run("room", "bob", "!poll capabilities"), "\n")
+	if !hasCapability(caps, "integration:members") {
+		t.Errorf("capabilities = %q, want integration:members", caps)
+	}
+	for _, lacking := range []string{"integration:files", "integration:blocks", "integration:tenure"} {
+		if hasCapability(caps, lacking) {
+			t.Errorf("capabilities = %q, want no %s", caps, lacking)
+		}
+	}
+}
+
+func TestCapabilitiesListTheUsage(t *testing.T) {
+	caps := Capabilities()
+	scanner := bufio.NewScanner(strings.NewReader(usage))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if command, ok := strings.CutPrefix(line, "!poll "); ok {
+			if name := strings.Fields(command)[0]; !hasCapability(caps, "command:"+name) {
+				t.Errorf("the command %s of the usage is not dispatched", name)
+			}
+		}
+		if flag, ok := strings.CutPrefix(line, "    -"); ok {
+			if name, _, _ := strings.Cut(strings.Fields(flag)[0], "="); !hasCapability(caps, "flag:"+name) {
+				t.Errorf("the flag -%s of the usage is not parsed", name)
+			}
+		}
+	}
+}
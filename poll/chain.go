@@ -0,0 +1,62 @@
+package poll
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxChain is the number of polls that can be chained after a poll. Chained
+// polls are copies of documents rather than references to other polls, so
+// they cannot form a cycle, and the limit keeps a chain from growing
+// forever.
+const maxChain = 10
+
+func (pl *Poller) pollChain(roomId, userId, data string) string {
+	doc, err := decodeDocument([]byte(data))
+	if err != nil {
+		return fmt.Sprintf("Could not chain the poll: %s.", strings.TrimPrefix(err.Error(), "poll: "))
+	}
+
+	pl.mutex.Lock()
+	defer pl.unlock()
+
+	poll, ok := pl.polls[roomId]
+	if !ok {
+		return "There is no poll."
+	}
+	if !pl.canManage(roomId, userId, poll) {
+		return "Only the creator of the poll or a poll admin can chain polls to it."
+	}
+	next := append([]pollDocument{doc}, doc.Next...)
+	next[0].Next = nil
+	if len(poll.Next)+len(next) > maxChain {
+		return fmt.Sprintf("At most %d polls can be chained to a poll.", maxChain)
+	}
+
+	poll.Next = append(poll.Next, next...)
+	titles := make([]string, 0, len(poll.Next))
+	for _, d := range poll.Next {
+		titles = append(titles, d.Title)
+	}
+	return fmt.Sprintf("Polls started after '%s' ends: %s", poll.viewedBy("").Title, strings.Join(titles, ", "))
+}
+
+// startNext creates the first poll chained to the ended poll, if any, and
+// starts it when it has enough options. It must be called with the mutex
+// held.
+func (pl *Poller) startNext(roomId string, ended *pollEntry) string {
+	if len(ended.Next) == 0 {
+		return ""
+	}
+	poll := ended.Next[0].entry(ended.Creator)
+	poll.Next = ended.Next[1:]
+	poll.origin = ended.origin
+	pl.addPoll(roomId, poll)
+
+	if distinct, _ := poll.distinctOptions(); distinct < 2 {
+		pl.armNudge(roomId, poll)
+		return fmt.Sprintf("Next poll '%s' created.\nUse !poll option <option> to add options.", poll.Title)
+	}
+	pl.activate(roomId, poll)
+	return fmt.Sprintf("Next poll:\n%s", poll.Details())
+}
@@ -0,0 +1,35 @@
+package poll
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestChain(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.start("room", "", "Pizza", "Tacos")
+
+	reply := tp.run("room", "alice", `!poll chain {"title": "Dessert?", "options": ["Cake", "Fruit"], "next": [{"title": "Coffee?"}]}`)
+	if reply != "Polls started after 'Lunch?' ends: Dessert?, Coffee?" {
+		t.Errorf("chain reply = %q", reply)
+	}
+	if reply := tp.run("room", "bob", `!poll chain {"title": "Tea?"}`); reply != "Only the creator of the poll or a poll admin can chain polls to it." {
+		t.Errorf("chain of a user reply = %q", reply)
+	}
+
+	reply = tp.run("room", "alice", "!poll end")
+	if !strings.HasSuffix(reply, "\nNext poll:\nDessert?\n 1. Cake (0 votes)\n 2. Fruit (0 votes)") {
+		t.Errorf("results = %q, want the next poll started", reply)
+	}
+	if err := tp.Vote("room", "bob", 1); err != nil {
+		t.Errorf("Vote in the next poll failed: %v", err)
+	}
+
+	// the last poll of the chain has no options to start with
+	if reply := tp.run("room", "alice", "!poll end"); !strings.HasSuffix(reply, "\nNext poll 'Coffee?' created.\nUse !poll option <option> to add options.") {
+		t.Errorf("results = %q, want the next poll created", reply)
+	}
+	if reply := tp.run("room", "alice", "!poll show"); !strings.HasPrefix(reply, "Poll (Inactive):\nCoffee?") {
+		t.Errorf("show = %q, want the draft of the last poll", reply)
+	}
+}
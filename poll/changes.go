@@ -0,0 +1,57 @@
+package poll
+
+import (
+	"fmt"
+	"strings"
+)
+
+// markSeen records the tallies the user is looking at, compared against by
+// !poll changes. It must be called with the mutex and seenMutex held, the
+// mutex possibly for reading.
+func (p *pollEntry) markSeen(userId string) {
+	if userId == "" {
+		return
+	}
+	tallies := make(map[string]int, len(p.Options))
+	for _, o := range p.Options {
+		tallies[optionKey(o.Text)] = o.Votes
+	}
+	if p.seen == nil {
+		p.seen = make(map[string]map[string]int)
+	}
+	p.seen[userId] = tallies
+}
+
+// pollChanges reports the votes cast since the user last looked at the poll
+// with show or changes, e.g. "Pizza +2, Tacos +1 since you last looked."
+func (pl *Poller) pollChanges(roomId, userId string) string {
+	pl.mutex.RLock()
+	defer pl.mutex.RUnlock()
+	pl.seenMutex.Lock()
+	defer pl.seenMutex.Unlock()
+
+	poll, ok := pl.polls[roomId]
+	if !ok {
+		return "There is no poll."
+	}
+	if poll.hidesResults(userId) {
+		return poll.hiddenMessage()
+	}
+
+	seen, ok := poll.seen[userId]
+	poll.markSeen(userId)
+	if !ok {
+		return "You have not looked at the poll yet, run !poll changes again later to see the new votes."
+	}
+
+	var deltas []string
+	for _, o := range poll.Options {
+		if d := o.Votes - seen[optionKey(o.Text)]; d != 0 {
+			deltas = append(deltas, fmt.Sprintf("%s %+d", o.Text, d))
+		}
+	}
+	if len(deltas) == 0 {
+		return "No new votes since you last looked."
+	}
+	return fmt.Sprintf("%s since you last looked.", strings.Join(deltas, ", "))
+}
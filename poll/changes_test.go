@@ -0,0 +1,26 @@
+package poll
+
+import "testing"
+
+func TestChanges(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.start("room", "", "Pizza", "Tacos", "Sushi")
+
+	if reply := tp.run("room", "bob", "!poll changes"); reply != "You have not looked at the poll yet, run !poll changes again later to see the new votes." {
+		t.Errorf("first changes = %q", reply)
+	}
+	tp.castVotes("room", 1, 2, 1)
+	if reply := tp.run("room", "bob", "!poll changes"); reply != "Pizza +2, Tacos +1 since you last looked." {
+		t.Errorf("changes = %q", reply)
+	}
+	if reply := tp.run("room", "bob", "!poll changes"); reply != "No new votes since you last looked." {
+		t.Errorf("changes without new votes = %q", reply)
+	}
+
+	tp.run("room", "carol", "!poll show")
+	tp.run("room", "eve", "!poll vote 1")
+	tp.run("room", "dave", "!poll vote 3")
+	if reply := tp.run("room", "carol", "!poll changes"); reply != "Pizza +1, Sushi +1 since you last looked." {
+		t.Errorf("changes since show = %q", reply)
+	}
+}
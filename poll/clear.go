@@ -0,0 +1,79 @@
+package poll
+
+import (
+	"fmt"
+	"time"
+)
+
+// pollClearInactive removes the poll of the room if it hasn't started, along
+// with the ended polls of the room retained for the digest and the board.
+// When the clear.archive pref is set, the ended polls are archived first.
+// The polls are only cleared once the user confirms.
+func (pl *Poller) pollClearInactive(roomId, userId string) string {
+	if !pl.isAdmin(roomId, userId) {
+		return "Only poll admins can clear the inactive polls."
+	}
+	return pl.confirmFirst(roomId, userId, "clear-inactive", func() string {
+		return pl.clearInactive(roomId)
+	})
+}
+
+// clearInactive clears the inactive polls of the room. The ended polls are
+// archived without holding the mutex, so those ending meanwhile are kept for
+// the next clear.
+func (pl *Poller) clearInactive(roomId string) string {
+	pl.mutex.RLock()
+	var closed []closedPoll
+	var records []archiveRecord
+	for _, c := range pl.closedPolls {
+		if c.RoomId == roomId {
+			closed = append(closed, c)
+			records = append(records, newArchiveRecord(roomId, &c.Poll))
+		}
+	}
+	pl.mutex.RUnlock()
+
+	if pl.pref(roomId, "clear.archive", "false") == "true" {
+		for k, record := range records {
+			if _, err := pl.saveArchive(record); err != nil {
+				return fmt.Sprintf("Could not archive %s, no poll was cleared: %v", closed[k].Poll.Title, err)
+			}
+		}
+	}
+
+	pl.mutex.Lock()
+	defer pl.unlock()
+
+	cleared := make(map[closedKey]bool, len(closed))
+	for _, c := range closed {
+		cleared[c.key()] = true
+	}
+	kept := make([]closedPoll, 0, len(pl.closedPolls))
+	for _, c := range pl.closedPolls {
+		if c.RoomId != roomId || !cleared[c.key()] {
+			kept = append(kept, c)
+		}
+	}
+	count := len(pl.closedPolls) - len(kept)
+	pl.closedPolls = kept
+	if poll, ok := pl.polls[roomId]; ok && !poll.IsActive {
+		poll.stopTimers()
+		delete(pl.polls, roomId)
+		count++
+	}
+
+	if count == 1 {
+		return "Cleared 1 inactive poll."
+	}
+	return fmt.Sprintf("Cleared %d inactive polls.", count)
+}
+
+// closedKey identifies a closed poll among those of its room.
+type closedKey struct {
+	code     string
+	closedAt time.Time
+}
+
+func (c closedPoll) key() closedKey {
+	return closedKey{code: c.Poll.Code, closedAt: c.ClosedAt}
+}
@@ -0,0 +1,71 @@
+package poll
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// confirm runs the command of the user in the room and confirms it with the
+// token of the reply.
+func (tp *testPoller) confirm(roomId, userId, body string) string {
+	tp.t.Helper()
+	reply := tp.run(roomId, userId, body)
+	_, token, ok := strings.Cut(reply, "Type !poll confirm ")
+	if !ok {
+		tp.t.Fatalf("%s reply = %q, want a confirmation token", body, reply)
+	}
+	token, _, _ = strings.Cut(token, " ")
+	return tp.run(roomId, userId, "!poll confirm "+token)
+}
+
+func TestClearInactive(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.prefs["room/admins"] = "root"
+	for k := 0; k < 2; k++ {
+		tp.start("room", "", "Pizza", "Tacos")
+		tp.run("room", "alice", "!poll end")
+	}
+	tp.start("room", "", "Soup", "Salad")
+	tp.castVotes("room", 1)
+
+	if reply := tp.run("room", "alice", "!poll clear-inactive"); reply != "Only poll admins can clear the inactive polls." {
+		t.Errorf("clear-inactive by a user = %q", reply)
+	}
+	if reply := tp.confirm("room", "root", "!poll clear-inactive"); reply != "Cleared 2 inactive polls." {
+		t.Errorf("clear-inactive reply = %q", reply)
+	}
+	if _, ok := tp.lastClosed("room"); ok {
+		t.Error("the ended polls were not cleared")
+	}
+	if got := tp.votes("room"); !reflect.DeepEqual(got, []int{1, 0}) {
+		t.Errorf("votes of the active poll = %v, want it untouched", got)
+	}
+}
+
+func TestClearInactiveArchives(t *testing.T) {
+	tp := newTestPoller(t)
+	s := newMemStorage()
+	SetStorage(s)
+	t.Cleanup(func() { SetStorage(nil) })
+	tp.prefs["room/admins"] = "root"
+	tp.prefs["room/clear.archive"] = "true"
+	for _, room := range []string{"room", "room", "other"} {
+		tp.start(room, "", "Pizza", "Tacos")
+		tp.run(room, "alice", "!poll end")
+	}
+	tp.run("room", "alice", "!poll new Dinner?")
+
+	if reply := tp.confirm("room", "root", "!poll clear-inactive"); reply != "Cleared 3 inactive polls." {
+		t.Errorf("clear-inactive reply = %q", reply)
+	}
+	if len(s.data) != 2 {
+		t.Errorf("archived %d polls, want the 2 ended in the room", len(s.data))
+	}
+	if tp.hasPoll("room") {
+		t.Error("the draft was not cleared")
+	}
+	if reply := tp.run("other", "bob", "!poll board"); !strings.Contains(reply, "closed") {
+		t.Errorf("board of the other room = %q, want its poll kept", reply)
+	}
+}
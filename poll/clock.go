@@ -0,0 +1,59 @@
+package poll
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock is the source of time used by the plugin.
+type Clock interface {
+	Now() time.Time
+	// AfterFunc calls f in its own goroutine after the duration elapses.
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Timer is a pending call scheduled with Clock.AfterFunc.
+type Timer interface {
+	Stop() bool
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) AfterFunc(d time.Duration, f func()) Timer {
+	return time.AfterFunc(d, f)
+}
+
+var (
+	clock      Clock = realClock{}
+	clockMutex sync.RWMutex
+)
+
+// SetClock replaces the clock used by the plugin, e.g. with a fake one in
+// tests. A nil clock restores the real one.
+func SetClock(c Clock) {
+	clockMutex.Lock()
+	defer clockMutex.Unlock()
+
+	if c == nil {
+		c = realClock{}
+	}
+	clock = c
+}
+
+// now returns the current time of the clock.
+func now() time.Time {
+	clockMutex.RLock()
+	defer clockMutex.RUnlock()
+	return clock.Now()
+}
+
+// afterFunc schedules f with the clock.
+func afterFunc(d time.Duration, f func()) Timer {
+	clockMutex.RLock()
+	defer clockMutex.RUnlock()
+	return clock.AfterFunc(d, f)
+}
@@ -0,0 +1,52 @@
+package poll
+
+import "math/rand"
+
+// Characters of the poll codes, leaving out those easily mistaken for one
+// another such as l and 1.
+const (
+	codeLetters = "abcdefghjkmnpqrstuvwxyz"
+	codeDigits  = "23456789"
+)
+
+// codeTries is the number of codes of a length tried before longer codes
+// are generated.
+const codeTries = 20
+
+// newCode generates a short code for a poll, such as "a3", unused by the
+// polls of the room, current or closed. It must be called with the mutex
+// held.
+func (pl *Poller) newCode(roomId string) string {
+	used := make(map[string]bool)
+	if poll, ok := pl.polls[roomId]; ok {
+		used[poll.Code] = true
+	}
+	for _, c := range pl.closedPolls {
+		if c.RoomId == roomId {
+			used[c.Poll.Code] = true
+		}
+	}
+
+	r := rand.New(rand.NewSource(now().UnixNano()))
+	for digits := 1; ; digits++ {
+		for try := 0; try < codeTries; try++ {
+			code := []byte{codeLetters[r.Intn(len(codeLetters))]}
+			for k := 0; k < digits; k++ {
+				code = append(code, codeDigits[r.Intn(len(codeDigits))])
+			}
+			if !used[string(code)] {
+				return string(code)
+			}
+		}
+	}
+}
+
+// addPoll makes the poll the poll of the room, with a new code. It must be
+// called with the mutex held.
+func (pl *Poller) addPoll(roomId string, poll *pollEntry) {
+	poll.Code = pl.newCode(roomId)
+	if pl.polls == nil {
+		pl.polls = make(map[string]*pollEntry)
+	}
+	pl.polls[roomId] = poll
+}
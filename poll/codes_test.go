@@ -0,0 +1,37 @@
+package poll
+
+import "testing"
+
+func TestNewCodeUnique(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.mutex.Lock()
+	defer tp.mutex.Unlock()
+
+	seen := make(map[string]bool)
+	for k := 0; k < 500; k++ {
+		code := tp.newCode("room")
+		if seen[code] {
+			t.Fatalf("code %s generated twice, after %d codes", code, k)
+		}
+		seen[code] = true
+		tp.closedPolls = append(tp.closedPolls, closedPoll{RoomId: "room", Poll: pollEntry{Code: code}})
+	}
+}
+
+func TestCodeStable(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.start("room", "", "Pizza", "Tacos")
+	tp.mutex.RLock()
+	code := tp.polls["room"].Code
+	tp.mutex.RUnlock()
+	if code == "" {
+		t.Fatal("the poll has no code")
+	}
+
+	tp.castVotes("room", 1, 2)
+	tp.run("room", "alice", "!poll end")
+	c, ok := tp.lastClosed("room")
+	if !ok || c.Poll.Code != code {
+		t.Errorf("ended poll = %+v, want the code %s", c.Poll, code)
+	}
+}
@@ -0,0 +1,72 @@
+package poll
+
+import "fmt"
+
+// pollCombine merges the votes of the poll of another room into the poll of
+// the room, matching the options by text and appending the others. A user
+// who voted in both polls keeps the vote cast in the room. The other poll is
+// left as it is. The user must be able to manage both polls, so that the
+// votes of a room are not read from another.
+func (pl *Poller) pollCombine(roomId, userId, otherRoomId string) string {
+	if otherRoomId == roomId {
+		return "Please give the id of another room."
+	}
+
+	pl.mutex.Lock()
+	defer pl.unlock()
+
+	poll, ok := pl.polls[roomId]
+	if !ok {
+		return "There is no poll."
+	}
+	other, ok := pl.polls[otherRoomId]
+	if !ok {
+		return fmt.Sprintf("There is no poll in room %s.", otherRoomId)
+	}
+	if !pl.canManage(roomId, userId, poll) || !pl.canManage(otherRoomId, userId, other) {
+		return "Only the creator or a poll admin of both polls can combine them."
+	}
+	if poll.Budget > 0 || other.Budget > 0 {
+		return "Polls allocating points cannot be combined."
+	}
+
+	hasVoted := make(map[string]bool, len(poll.HasVoted))
+	for _, uId := range poll.HasVoted {
+		hasVoted[uId] = true
+	}
+	indices := make(map[string]int, len(poll.Options))
+	for k, o := range poll.Options {
+		indices[optionKey(o.Text)] = k
+	}
+	// the options of the other poll map to those of the poll, appended
+	// where they have no match, voted for or not
+	mapped := make([]int, len(other.Options))
+	for k, o := range other.Options {
+		index, ok := indices[optionKey(o.Text)]
+		if !ok {
+			poll.Options = append(poll.Options, pollOption{Text: o.Text})
+			index = len(poll.Options) - 1
+			indices[optionKey(o.Text)] = index
+		}
+		mapped[k] = index
+	}
+
+	added, skipped := 0, 0
+	for _, b := range other.Ballots {
+		if hasVoted[b.UserId] {
+			skipped++
+			continue
+		}
+		k := mapped[b.Option]
+		poll.Options[k].Votes++
+		poll.HasVoted = append(poll.HasVoted, b.UserId)
+		poll.Ballots = append(poll.Ballots, ballot{UserId: b.UserId, UserName: b.UserName, Option: k})
+		hasVoted[b.UserId] = true
+		added++
+	}
+	pl.checkTally(roomId, poll)
+	poll.audit(userId, "combined %d votes of the poll of room %s, skipped %d voters who voted in both", added, otherRoomId, skipped)
+
+	return fmt.Sprintf("Combined %d votes from room %s, %d voters had already voted here.\n%s",
+		added, otherRoomId, skipped, poll.viewedBy("").Result())
+}
@@ -0,0 +1,61 @@
+package poll
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCombine(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.start("room", "", "Pizza", "Tacos")
+	tp.start("other", "", "pizza", "Tacos", "Sushi", "Curry")
+	tp.run("room", "bob", "!poll vote 1")
+	tp.run("other", "bob", "!poll vote 2")
+	tp.run("other", "carol", "!poll vote 1")
+	tp.run("other", "dave", "!poll vote 3")
+
+	reply := tp.run("room", "alice", "!poll combine other")
+	if !strings.HasPrefix(reply, "Combined 2 votes from room other, 1 voters had already voted here.") {
+		t.Errorf("combine = %q", reply)
+	}
+	// Curry has no votes to combine, but is appended all the same
+	if got, want := tp.votes("room"), []int{2, 0, 1, 0}; !reflect.DeepEqual(got, want) {
+		t.Errorf("votes = %v, want %v", got, want)
+	}
+	if !strings.Contains(reply, "Curry (0 votes)") {
+		t.Errorf("combine = %q, want the options of the other poll appended", reply)
+	}
+	// bob is counted once, with the vote cast in the room
+	tp.clock.Advance(time.Minute)
+	if reply := tp.run("room", "carol", "!poll vote 2"); reply != "You have already voted." {
+		t.Errorf("vote of a combined voter = %q", reply)
+	}
+	if got := tp.votes("other"); !reflect.DeepEqual(got, []int{1, 1, 1, 0}) {
+		t.Errorf("votes of the other poll = %v, want it left as it is", got)
+	}
+}
+
+func TestCombineManagers(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.start("room", "", "Pizza", "Tacos")
+	tp.run("other", "carol", "!poll new Lunch?")
+	tp.run("other", "carol", "!poll option Pizza")
+
+	refused := "Only the creator or a poll admin of both polls can combine them."
+	if reply := tp.run("room", "bob", "!poll combine other"); reply != refused {
+		t.Errorf("combine by a voter = %q", reply)
+	}
+	if reply := tp.run("room", "alice", "!poll combine other"); reply != refused {
+		t.Errorf("combine by the creator of one of the polls = %q", reply)
+	}
+	tp.prefs["room/admins"] = "root"
+	if reply := tp.run("room", "root", "!poll combine other"); reply != refused {
+		t.Errorf("combine by an admin of one of the rooms = %q", reply)
+	}
+	tp.prefs["other/admins"] = "alice"
+	if reply := tp.run("room", "alice", "!poll combine other"); !strings.HasPrefix(reply, "Combined 0 votes") {
+		t.Errorf("combine by the creator and an admin of the other room = %q", reply)
+	}
+}
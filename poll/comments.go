@@ -0,0 +1,52 @@
+package poll
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// maxComments is the number of comments retained per poll. Older comments
+// are dropped first.
+const maxComments = 50
+
+type comment struct {
+	Time   time.Time
+	Author string
+	Text   string
+}
+
+func (pl *Poller) pollAddComment(roomId, author, text string) string {
+	pl.mutex.Lock()
+	defer pl.unlock()
+
+	poll, ok := pl.polls[roomId]
+	if !ok {
+		return "There is no poll."
+	}
+
+	poll.Comments = append(poll.Comments, comment{Time: now(), Author: author, Text: text})
+	if len(poll.Comments) > maxComments {
+		poll.Comments = poll.Comments[len(poll.Comments)-maxComments:]
+	}
+	return "Comment added."
+}
+
+func (pl *Poller) pollComments(roomId string) string {
+	pl.mutex.RLock()
+	defer pl.mutex.RUnlock()
+
+	poll, ok := pl.polls[roomId]
+	if !ok {
+		return "There is no poll."
+	}
+	if len(poll.Comments) == 0 {
+		return "There are no comments."
+	}
+
+	lines := make([]string, 0, len(poll.Comments))
+	for _, c := range poll.Comments {
+		lines = append(lines, fmt.Sprintf(" %s %s: %s", c.Time.Format(time.RFC3339), c.Author, c.Text))
+	}
+	return fmt.Sprintf("Comments on %s:\n%s", poll.viewedBy("").Title, strings.Join(lines, "\n"))
+}
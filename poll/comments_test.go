@@ -0,0 +1,32 @@
+package poll
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestComments(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.run("room", "alice", "!poll new Lunch?")
+
+	if reply := tp.run("room", "bob", "!poll comments"); reply != "There are no comments." {
+		t.Errorf("comments of a new poll = %q", reply)
+	}
+	for k := 1; k <= maxComments+1; k++ {
+		if reply := tp.run("room", "bob", fmt.Sprintf("!poll comment remark %d", k)); reply != "Comment added." {
+			t.Fatalf("comment reply = %q", reply)
+		}
+	}
+
+	lines := strings.Split(tp.run("room", "carol", "!poll comments"), "\n")
+	if lines[0] != "Comments on Lunch?:" || len(lines) != maxComments+1 {
+		t.Fatalf("comments = %q, want the %d last comments", lines, maxComments)
+	}
+	if want := " 2024-03-04T10:00:00Z bob: remark 2"; lines[1] != want {
+		t.Errorf("oldest comment = %q, want %q", lines[1], want)
+	}
+	if want := fmt.Sprintf(" 2024-03-04T10:00:00Z bob: remark %d", maxComments+1); lines[maxComments] != want {
+		t.Errorf("newest comment = %q, want %q", lines[maxComments], want)
+	}
+}
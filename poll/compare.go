@@ -0,0 +1,68 @@
+package poll
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// pollCompare shows the results of the poll next to those of the ended poll
+// with the code, matching the options by text. Options of only one of the
+// polls are marked as new or gone.
+func (pl *Poller) pollCompare(roomId, userId, code string) string {
+	ended, _, err := pl.findEnded(roomId, code)
+	if errors.Is(err, ErrNotFound) {
+		return fmt.Sprintf("There is no ended poll %s.", code)
+	}
+	if err != nil {
+		return fmt.Sprintf("Could not read the archived poll %s: %v", code, err)
+	}
+
+	pl.mutex.RLock()
+	defer pl.mutex.RUnlock()
+
+	poll, ok := pl.polls[roomId]
+	if !ok {
+		return "There is no poll."
+	}
+	if poll.hidesResults(userId) {
+		return poll.hiddenMessage()
+	}
+
+	before := make(map[string]int, len(ended.Options))
+	for _, o := range ended.Options {
+		before[optionKey(o.Text)] = o.Votes
+	}
+	lines := make([]string, 0, len(poll.Options)+len(ended.Options))
+	matched := make(map[string]bool, len(poll.Options))
+	common := 0
+	for _, o := range poll.Options {
+		key := optionKey(o.Text)
+		votes, ok := before[key]
+		if ok {
+			common++
+		}
+		switch {
+		case !ok:
+			lines = append(lines, fmt.Sprintf(" %s: %s (new)", o.Text, formatCount(o.Votes)))
+		case o.Votes == votes:
+			lines = append(lines, fmt.Sprintf(" %s: %s (unchanged)", o.Text, formatCount(o.Votes)))
+		default:
+			lines = append(lines, fmt.Sprintf(" %s: %s (was %s, %+d)", o.Text, formatCount(o.Votes), formatCount(votes), o.Votes-votes))
+		}
+		matched[key] = true
+	}
+	for _, o := range ended.Options {
+		if !matched[optionKey(o.Text)] {
+			lines = append(lines, fmt.Sprintf(" %s: gone (was %s)", o.Text, formatCount(o.Votes)))
+		}
+	}
+	if len(lines) == 0 {
+		return "Neither poll has options."
+	}
+	compared := fmt.Sprintf("%s compared with [%s] %s:\n%s", poll.viewedBy("").Title, code, ended.Title, strings.Join(lines, "\n"))
+	if common == 0 {
+		compared = fmt.Sprintf("%s\nThe polls have no options in common.", compared)
+	}
+	return compared
+}
@@ -0,0 +1,65 @@
+package poll
+
+import "testing"
+
+func TestCompare(t *testing.T) {
+	tp := newTestPoller(t)
+	t.Cleanup(func() { SetStorage(nil) })
+	SetStorage(newMemStorage())
+	tp.start("room", "", "Pizza", "Tacos", "Sushi")
+	tp.castVotes("room", 1, 2, 2, 3)
+	id := tp.archive("room")
+
+	tp.start("room", "", "Pizza", "Tacos", "Curry")
+	tp.castVotes("room", 1, 1, 1, 2, 2)
+	want := "Lunch? compared with [" + id + "] Lunch?:\n" +
+		" Pizza: 3 (was 1, +2)\n" +
+		" Tacos: 2 (unchanged)\n" +
+		" Curry: 0 (new)\n" +
+		" Sushi: gone (was 1)"
+	if reply := tp.run("room", "bob", "!poll compare "+id); reply != want {
+		t.Errorf("compare = %q, want %q", reply, want)
+	}
+}
+
+func TestCompareMismatched(t *testing.T) {
+	tp := newTestPoller(t)
+	t.Cleanup(func() { SetStorage(nil) })
+	SetStorage(newMemStorage())
+	tp.start("room", "", "Pizza", "Tacos")
+	id := tp.archive("room")
+	tp.start("room", "", "Soup", "Salad")
+
+	want := "Lunch? compared with [" + id + "] Lunch?:\n" +
+		" Soup: 0 (new)\n" +
+		" Salad: 0 (new)\n" +
+		" Pizza: gone (was 0)\n" +
+		" Tacos: gone (was 0)\n" +
+		"The polls have no options in common."
+	if reply := tp.run("room", "bob", "!poll compare "+id); reply != want {
+		t.Errorf("compare = %q, want %q", reply, want)
+	}
+	if reply := tp.run("room", "bob", "!poll compare nope"); reply != "There is no ended poll nope." {
+		t.Errorf("compare with an unknown id = %q", reply)
+	}
+}
+
+func TestCompareEnded(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.start("room", "", "Pizza", "Tacos")
+	tp.castVotes("room", 1)
+	tp.run("room", "alice", "!poll end")
+	c, _ := tp.lastClosed("room")
+
+	tp.start("room", "", "Pizza", "Tacos")
+	tp.castVotes("room", 1, 1)
+	want := "Lunch? compared with [" + c.Poll.Code + "] Lunch?:\n" +
+		" Pizza: 2 (was 1, +1)\n" +
+		" Tacos: 0 (unchanged)"
+	if reply := tp.run("room", "bob", "!poll compare "+c.Poll.Code); reply != want {
+		t.Errorf("compare = %q, want %q", reply, want)
+	}
+	if reply := tp.run("other", "bob", "!poll compare "+c.Poll.Code); reply != "There is no ended poll "+c.Poll.Code+"." {
+		t.Errorf("compare from another room = %q, want it not found", reply)
+	}
+}
@@ -0,0 +1,117 @@
+package poll
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// pollConfig lists the modes the poll was created with, one per line.
+func (pl *Poller) pollConfig(roomId, userId string) string {
+	pl.mutex.RLock()
+	defer pl.mutex.RUnlock()
+
+	poll, ok := pl.polls[roomId]
+	if !ok {
+		return "There is no poll."
+	}
+
+	modes := poll.modes()
+	if len(modes) == 0 {
+		return fmt.Sprintf("%s uses the default configuration.", poll.viewedBy(userId).Title)
+	}
+	return fmt.Sprintf("Configuration of %s:\n %s", poll.viewedBy(userId).Title, strings.Join(modes, "\n "))
+}
+
+// modes describes the flags of the poll that differ from the defaults.
+func (p *pollEntry) modes() []string {
+	var modes []string
+	add := func(format string, a ...interface{}) {
+		modes = append(modes, fmt.Sprintf(format, a...))
+	}
+	if len(p.Allow) > 0 {
+		add("allowlist: %s and the creator", strings.Join(p.Allow, ", "))
+	}
+	if p.Winners > 0 {
+		add("winners: the top %d options", p.Winners)
+	}
+	if p.Quiet {
+		add("quiet: votes are confirmed without the results")
+	}
+	switch {
+	case !p.Deadline.IsZero():
+		add("deadline: closes at %s", p.Deadline.Format(time.RFC3339))
+	case p.Duration > 0:
+		add("duration: closes %s after it starts", p.Duration)
+	}
+	if p.MinOptions > 0 {
+		add("min: at least %d options to start", p.MinOptions)
+	}
+	if p.Open {
+		add("open: the ballots are revealed at the end")
+	}
+	if p.TieBreak != "" {
+		add("tiebreak: %s, seed %d", p.TieBreak, p.Seed)
+	}
+	if p.Quiz {
+		add("quiz: the answer is revealed at the end")
+	}
+	if p.Raffle {
+		add("raffle: the winner is drawn weighted by the votes, seed %d", p.Seed)
+	}
+	if p.MinTenure > 0 {
+		add("mintenure: voters joined at least %s ago", p.MinTenure)
+	}
+	if p.AutoClose {
+		add("autoclose: closes once every member voted")
+	}
+	if len(p.Verbs) > 0 {
+		add("verbs: %s", strings.Join(p.Verbs, ", "))
+	}
+	if p.SecretTitle {
+		add("secrettitle: the title is hidden until the end")
+	}
+	if p.Budget > 0 {
+		add("budget: %d points per voter", p.Budget)
+	}
+	if p.Reactions {
+		add("reactions: reactions to the poll message count as votes")
+	}
+	if p.SnapshotEvery > 0 {
+		add("snapshot: the tally is recorded every %s", p.SnapshotEvery)
+	}
+	if p.Ack != "" {
+		add("ack: voters acknowledge %q", p.Ack)
+	}
+	if p.Quorum > 0 {
+		add("quorum: %d voters", p.Quorum)
+	}
+	if p.QuorumPercent > 0 {
+		add("quorum: %d%% of the members", p.QuorumPercent)
+	}
+	if p.HideUntilVote {
+		add("hideuntilvote: the results are shown to the users who voted")
+	}
+	if len(p.Abstainers) > 0 {
+		add("reopened: only %s may vote", strings.Join(p.Abstainers, ", "))
+	}
+	if p.Pin {
+		add("pin: the poll message is pinned while the poll runs")
+	}
+	if p.AntiSnipe > 0 {
+		add("antisnipe: late votes push the deadline out by %s, %d times so far", p.AntiSnipe, p.Extensions)
+	}
+	if p.LockVotes > 0 {
+		add("lockvotes: votes can change for %s after the start", p.LockVotes)
+	}
+	if p.Federation != "" {
+		add("federation: %s, the results are posted to every member room", p.Federation)
+	}
+	if p.HiddenMessage != "" {
+		add("hiddenmsg: %q is shown until the results are", p.HiddenMessage)
+	}
+	if p.OptionsLocked {
+		add("options locked: only the creator adds options")
+	}
+	return modes
+}
@@ -0,0 +1,31 @@
+package poll
+
+import "testing"
+
+func TestConfig(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.run("room", "alice", "!poll new Lunch?")
+	if reply := tp.run("room", "bob", "!poll config"); reply != "Lunch? uses the default configuration." {
+		t.Errorf("config of a default poll = %q", reply)
+	}
+
+	tp.start("room", "-force -hideuntilvote -quorum=3 -duration=1h", "Pizza", "Tacos")
+	want := "Configuration of Lunch?:\n" +
+		" deadline: closes at 2024-03-04T11:00:00Z\n" +
+		" quorum: 3 voters\n" +
+		" hideuntilvote: the results are shown to the users who voted"
+	if reply := tp.run("room", "bob", "!poll config"); reply != want {
+		t.Errorf("config = %q, want %q", reply, want)
+	}
+}
+
+func TestConfigSecretTitle(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.run("room", "alice", "!poll new -secrettitle -quorum=50% Lunch?")
+	want := "Configuration of " + secretTitle + ":\n" +
+		" secrettitle: the title is hidden until the end\n" +
+		" quorum: 50% of the members"
+	if reply := tp.run("room", "bob", "!poll config"); reply != want {
+		t.Errorf("config = %q, want %q", reply, want)
+	}
+}
@@ -0,0 +1,76 @@
+package poll
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// confirmTTL is how long the token of a destructive command can be confirmed
+// with !poll confirm.
+const confirmTTL = 2 * time.Minute
+
+// tokenLength is the number of characters of a confirmation token.
+const tokenLength = 6
+
+// pendingAction is a destructive command waiting for its user to confirm it.
+type pendingAction struct {
+	command string
+	token   string
+	expires time.Time
+	run     func() string
+}
+
+// confirmFirst holds the destructive command of the user until it is
+// confirmed with the returned token, run then runs it. A pending command of
+// the user in the room is replaced.
+func (pl *Poller) confirmFirst(roomId, userId, command string, run func() string) string {
+	pl.mutex.Lock()
+	defer pl.unlock()
+
+	now := now()
+	for key, p := range pl.pending {
+		if !now.Before(p.expires) {
+			delete(pl.pending, key)
+		}
+	}
+	if pl.pending == nil {
+		pl.pending = make(map[string]pendingAction)
+	}
+
+	r := rand.New(rand.NewSource(now.UnixNano()))
+	token := make([]byte, tokenLength)
+	for k := range token {
+		token[k] = (codeLetters + codeDigits)[r.Intn(len(codeLetters)+len(codeDigits))]
+	}
+	pl.pending[roomId+"/"+userId] = pendingAction{
+		command: command,
+		token:   string(token),
+		expires: now.Add(confirmTTL),
+		run:     run,
+	}
+	return fmt.Sprintf("!poll %s cannot be undone. Type !poll confirm %s within %d minutes to proceed.", command, token, int(confirmTTL.Minutes()))
+}
+
+// pollConfirm runs the pending destructive command of the user if the token
+// matches and hasn't expired.
+func (pl *Poller) pollConfirm(roomId, userId, token string) string {
+	pl.mutex.Lock()
+	key := roomId + "/" + userId
+	p, ok := pl.pending[key]
+	if !ok {
+		pl.unlock()
+		return "There is nothing to confirm."
+	}
+	if p.token != token {
+		pl.unlock()
+		return fmt.Sprintf("Wrong token, type !poll confirm followed by the token given by !poll %s.", p.command)
+	}
+	delete(pl.pending, key)
+	pl.unlock()
+
+	if !now().Before(p.expires) {
+		return fmt.Sprintf("The confirmation expired, run !poll %s again.", p.command)
+	}
+	return p.run()
+}
@@ -0,0 +1,57 @@
+package poll
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConfirmRunsAfterTheMatchingToken(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.prefs["room/admins"] = "root"
+	tp.start("room", "", "Pizza", "Tacos")
+	tp.run("room", "alice", "!poll end")
+
+	reply := tp.run("room", "root", "!poll clear-inactive")
+	if !strings.HasPrefix(reply, "!poll clear-inactive cannot be undone. Type !poll confirm ") {
+		t.Fatalf("clear-inactive reply = %q", reply)
+	}
+	if _, ok := tp.lastClosed("room"); !ok {
+		t.Fatal("the ended poll was cleared before the confirmation")
+	}
+
+	if reply := tp.run("room", "root", "!poll confirm nope"); !strings.HasPrefix(reply, "Wrong token") {
+		t.Errorf("confirm with a wrong token = %q", reply)
+	}
+	if reply := tp.run("room", "bob", "!poll confirm nope"); reply != "There is nothing to confirm." {
+		t.Errorf("confirm by another user = %q", reply)
+	}
+	if _, ok := tp.lastClosed("room"); !ok {
+		t.Fatal("the ended poll was cleared without the matching token")
+	}
+
+	if reply := tp.confirm("room", "root", "!poll clear-inactive"); reply != "Cleared 1 inactive poll." {
+		t.Errorf("confirm reply = %q", reply)
+	}
+	if reply := tp.run("room", "root", "!poll confirm nope"); reply != "There is nothing to confirm." {
+		t.Errorf("second confirm = %q", reply)
+	}
+}
+
+func TestConfirmExpires(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.prefs["room/admins"] = "root"
+	tp.start("room", "", "Pizza", "Tacos")
+	tp.run("room", "alice", "!poll end")
+
+	reply := tp.run("room", "root", "!poll clear-inactive")
+	_, token, _ := strings.Cut(reply, "Type !poll confirm ")
+	token, _, _ = strings.Cut(token, " ")
+
+	tp.clock.Advance(confirmTTL)
+	if reply := tp.run("room", "root", "!poll confirm "+token); reply != "The confirmation expired, run !poll clear-inactive again." {
+		t.Errorf("confirm after %v = %q", confirmTTL, reply)
+	}
+	if _, ok := tp.lastClosed("room"); !ok {
+		t.Error("the ended poll was cleared by an expired token")
+	}
+}
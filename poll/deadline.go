@@ -0,0 +1,131 @@
+package poll
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// armDeadline sets the deadline of a poll with a duration and schedules its
+// automatic end. It must be called with the mutex held.
+func (pl *Poller) armDeadline(roomId string, poll *pollEntry) {
+	if poll.Duration <= 0 {
+		return
+	}
+	poll.Deadline = now().Add(poll.Duration)
+	pl.scheduleClose(roomId, poll)
+}
+
+// scheduleClose (re)schedules the automatic end of the poll at its deadline.
+// It must be called with the mutex held.
+func (pl *Poller) scheduleClose(roomId string, poll *pollEntry) {
+	if poll.closer != nil {
+		poll.closer.Stop()
+	}
+	deadline := poll.Deadline
+	poll.closer = afterFunc(deadline.Sub(now()), func() {
+		pl.fetchMembers(roomId)
+		pl.mutex.Lock()
+		current, ok := pl.polls[roomId]
+		if !ok || current != poll || !poll.IsActive || !poll.Deadline.Equal(deadline) {
+			pl.unlock()
+			return
+		}
+		results := pl.endPoll(roomId, poll, roomId)
+		origin := poll.origin
+		pl.unlock()
+
+		if origin.Broker != nil {
+			pl.replyResults(origin, fmt.Sprintf("Time is up! %s", results))
+		}
+	})
+}
+
+// preventSniping pushes the deadline of a poll created with -antisnipe out
+// when a vote comes in close to it, so that a last-second vote can still be
+// answered. The antisnipe.max pref caps the extensions. It must be called
+// with the mutex held.
+func (pl *Poller) preventSniping(roomId string, poll *pollEntry, at time.Time) {
+	if poll.AntiSnipe <= 0 || poll.Deadline.IsZero() || poll.Deadline.Sub(at) > poll.AntiSnipe {
+		return
+	}
+	max, err := strconv.Atoi(pl.pref(roomId, "antisnipe.max", "3"))
+	if err != nil || poll.Extensions >= max {
+		return
+	}
+	poll.Extensions++
+	poll.Deadline = poll.Deadline.Add(poll.AntiSnipe)
+	pl.scheduleClose(roomId, poll)
+	poll.audit("", "extended the deadline by %s after a late vote", formatDuration(poll.AntiSnipe))
+}
+
+// stopTimers cancels the pending reminder, automatic end, snapshot and
+// countdown of the poll.
+func (p *pollEntry) stopTimers() {
+	p.stopNudge()
+	if p.closer != nil {
+		p.closer.Stop()
+		p.closer = nil
+	}
+	if p.snapshotter != nil {
+		p.snapshotter.Stop()
+		p.snapshotter = nil
+	}
+	if p.countdown != nil {
+		p.countdown.Stop()
+		p.countdown = nil
+	}
+}
+
+// formatDuration renders a duration rounded to the second, e.g. "4m30s".
+func formatDuration(d time.Duration) string {
+	if d < time.Second {
+		return "less than a second"
+	}
+	return d.Round(time.Second).String()
+}
+
+func (pl *Poller) pollTime(roomId string) string {
+	pl.mutex.RLock()
+	defer pl.mutex.RUnlock()
+
+	poll, ok := pl.polls[roomId]
+	if !ok {
+		return "There is no poll."
+	}
+	if poll.Duration <= 0 {
+		return "No deadline set."
+	}
+	if !poll.IsActive {
+		return fmt.Sprintf("The poll will close %s after it is started.", formatDuration(poll.Duration))
+	}
+	return fmt.Sprintf("Closes in %s", formatDuration(poll.Deadline.Sub(now())))
+}
+
+func (pl *Poller) pollExtend(roomId, userId string, d time.Duration) string {
+	if d <= 0 {
+		return "The extension must be a positive duration such as 5m."
+	}
+
+	pl.mutex.Lock()
+	defer pl.unlock()
+
+	poll, ok := pl.polls[roomId]
+	if !ok {
+		return "There is no poll."
+	}
+	if !pl.canManage(roomId, userId, poll) {
+		return "Only the creator of the poll or a poll admin can extend it."
+	}
+	if !poll.IsActive {
+		return "There is no active poll."
+	}
+	if poll.Duration <= 0 {
+		return "The poll has no deadline to extend."
+	}
+
+	poll.Deadline = poll.Deadline.Add(d)
+	pl.scheduleClose(roomId, poll)
+
+	return fmt.Sprintf("Poll extended by %s, closes in %s", formatDuration(d), formatDuration(poll.Deadline.Sub(now())))
+}
@@ -0,0 +1,145 @@
+package poll
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimeLeft(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.run("room", "alice", "!poll new -duration=10m Lunch?")
+	if reply := tp.run("room", "bob", "!poll time"); reply != "The poll will close 10m0s after it is started." {
+		t.Errorf("time of a draft = %q", reply)
+	}
+	tp.run("room", "alice", "!poll option Pizza")
+	tp.run("room", "alice", "!poll option Tacos")
+	tp.run("room", "alice", "!poll start")
+
+	tp.clock.Advance(5*time.Minute + 30*time.Second)
+	if reply := tp.run("room", "bob", "!poll time"); reply != "Closes in 4m30s" {
+		t.Errorf("time = %q, want Closes in 4m30s", reply)
+	}
+	tp.clock.Advance(4*time.Minute + 29*time.Second + 600*time.Millisecond)
+	if reply := tp.run("room", "bob", "!poll time"); reply != "Closes in less than a second" {
+		t.Errorf("time just before the deadline = %q", reply)
+	}
+
+	tp.start("other", "", "Pizza", "Tacos")
+	if reply := tp.run("other", "bob", "!poll time"); reply != "No deadline set." {
+		t.Errorf("time without a deadline = %q", reply)
+	}
+}
+
+func TestDeadlineEndsPoll(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.start("room", "-duration=10m", "Pizza", "Tacos")
+	tp.run("room", "bob", "!poll vote 1")
+
+	tp.clock.Advance(9 * time.Minute)
+	if !tp.hasPoll("room") {
+		t.Fatal("the poll ended before its deadline")
+	}
+
+	n := tp.broker.count()
+	tp.clock.Advance(time.Minute)
+	if tp.hasPoll("room") {
+		t.Fatal("the poll is still running after its deadline")
+	}
+	msgs := tp.broker.since(n)
+	if len(msgs) != 1 || msgs[0].RoomId != "room" || !strings.HasPrefix(msgs[0].Body, "Time is up! Poll finished") {
+		t.Errorf("messages at the deadline = %+v, want the results", msgs)
+	}
+}
+
+func TestRemoveStopsDeadline(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.start("room", "-duration=10m", "Pizza", "Tacos")
+	tp.run("room", "alice", "!poll remove")
+
+	n := tp.broker.count()
+	tp.clock.Advance(time.Hour)
+	if msgs := tp.broker.since(n); len(msgs) > 0 {
+		t.Errorf("messages after removing the poll = %+v, want none", msgs)
+	}
+}
+
+func TestExtendMovesDeadline(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.start("room", "-duration=10m", "Pizza", "Tacos")
+
+	if reply := tp.run("room", "bob", "!poll extend 5m"); reply != "Only the creator of the poll or a poll admin can extend it." {
+		t.Errorf("extend of a user reply = %q", reply)
+	}
+	if reply := tp.run("room", "alice", "!poll extend -5m"); reply != "The extension must be a positive duration such as 5m." {
+		t.Errorf("negative extend reply = %q", reply)
+	}
+	if reply := tp.run("room", "alice", "!poll extend 5m"); reply != "Poll extended by 5m0s, closes in 15m0s" {
+		t.Errorf("extend reply = %q", reply)
+	}
+	tp.clock.Advance(10 * time.Minute)
+	if !tp.hasPoll("room") {
+		t.Fatal("the poll ended at its former deadline")
+	}
+	tp.clock.Advance(5 * time.Minute)
+	if tp.hasPoll("room") {
+		t.Fatal("the poll is still running after its extended deadline")
+	}
+}
+
+func TestExtendWithoutDeadline(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.start("room", "", "Pizza", "Tacos")
+	if reply := tp.run("room", "alice", "!poll extend 5m"); reply != "The poll has no deadline to extend." {
+		t.Errorf("extend reply = %q", reply)
+	}
+}
+
+func TestAntiSnipe(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.prefs["room/antisnipe.max"] = "2"
+	started := tp.clock.Now()
+	tp.start("room", "-duration=10m -antisnipe=1m", "Pizza", "Tacos")
+
+	tp.clock.Advance(9*time.Minute + 30*time.Second)
+	tp.run("room", "bob", "!poll vote 1")
+	tp.clock.Advance(time.Minute)
+	tp.run("room", "carol", "!poll vote 2")
+	tp.clock.Advance(time.Minute)
+	tp.run("room", "dave", "!poll vote 2")
+
+	tp.mutex.RLock()
+	poll := tp.polls["room"]
+	extensions, deadline := poll.Extensions, poll.Deadline
+	tp.mutex.RUnlock()
+	if extensions != 2 {
+		t.Errorf("extensions = %d, want the antisnipe.max of 2", extensions)
+	}
+	if want := started.Add(12 * time.Minute); !deadline.Equal(want) {
+		t.Errorf("deadline = %s, want %s", deadline, want)
+	}
+	tp.clock.Advance(time.Minute)
+	if tp.hasPoll("room") {
+		t.Fatal("the poll is still running after its last extended deadline")
+	}
+}
+
+func TestAntiSnipeEarlyVote(t *testing.T) {
+	tp := newTestPoller(t)
+	started := tp.clock.Now()
+	tp.start("room", "-duration=10m -antisnipe=1m", "Pizza", "Tacos")
+
+	tp.clock.Advance(8 * time.Minute)
+	tp.run("room", "bob", "!poll vote 1")
+
+	tp.mutex.RLock()
+	poll := tp.polls["room"]
+	extensions, deadline := poll.Extensions, poll.Deadline
+	tp.mutex.RUnlock()
+	if extensions != 0 {
+		t.Errorf("extensions = %d, want none for a vote 2m before the deadline", extensions)
+	}
+	if want := started.Add(10 * time.Minute); !deadline.Equal(want) {
+		t.Errorf("deadline = %s, want %s", deadline, want)
+	}
+}
@@ -0,0 +1,61 @@
+package poll
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// pollDebug dumps the internal state of the poll, for admins looking into a
+// discrepancy. The voters of polls that don't reveal the votes are counted
+// but not named, unless forced.
+func (pl *Poller) pollDebug(roomId, userId string, force bool) string {
+	if !pl.isAdmin(roomId, userId) {
+		return "Only poll admins can debug the poll."
+	}
+
+	pl.mutex.RLock()
+	defer pl.mutex.RUnlock()
+
+	poll, ok := pl.polls[roomId]
+	if !ok {
+		return "There is no poll."
+	}
+
+	lines := []string{
+		fmt.Sprintf("Title: %q, code: %s", poll.Title, poll.Code),
+		fmt.Sprintf("Creator: %s", poll.Creator),
+		fmt.Sprintf("Active: %t, started: %s, deadline: %s", poll.IsActive, debugTime(poll.StartedAt), debugTime(poll.Deadline)),
+		fmt.Sprintf("Flags: allow=%s winners=%d quiet=%t duration=%s min=%d open=%t tiebreak=%q seed=%d quiz=%t answer=%d raffle=%t mintenure=%s lockvotes=%s optionslocked=%t",
+			strings.Join(poll.Allow, ","), poll.Winners, poll.Quiet, poll.Duration, poll.MinOptions, poll.Open, poll.TieBreak,
+			poll.Seed, poll.Quiz, poll.Answer, poll.Raffle, poll.MinTenure, poll.LockVotes, poll.OptionsLocked),
+		fmt.Sprintf("Next polls: %d, comments: %d, audit records: %d", len(poll.Next), len(poll.Comments), len(poll.Audit)),
+		"Options:",
+	}
+	total := 0
+	for k, o := range poll.Options {
+		lines = append(lines, fmt.Sprintf(" %d. %q alias=%q url=%q votes=%d", k+1, o.Text, o.Alias, o.URL, o.Votes))
+		total += o.Votes
+	}
+	lines = append(lines, fmt.Sprintf("Votes: %d, voters: %d, ballots: %d, timeline: %d",
+		total, len(poll.HasVoted), len(poll.Ballots), len(poll.Timeline)))
+
+	if poll.Open || force {
+		lines = append(lines, "Ballots:")
+		for _, b := range poll.Ballots {
+			lines = append(lines, fmt.Sprintf(" %s (%s) → %d", b.UserId, b.UserName, b.Option+1))
+		}
+		lines = append(lines, fmt.Sprintf("HasVoted: %s", strings.Join(poll.HasVoted, ", ")))
+	} else {
+		lines = append(lines, "Voters are hidden as the poll is anonymous, use !poll debug -force to show them.")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// debugTime renders a time of the poll, or "-" if it is not set.
+func debugTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Format(time.RFC3339)
+}
@@ -0,0 +1,38 @@
+package poll
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDebug(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.prefs["room/admins"] = "root"
+	tp.start("room", "-winners=1", "Pizza", "Tacos")
+	tp.run("room", "bob", "!poll vote 2")
+
+	if reply := tp.run("room", "bob", "!poll debug"); reply != "Only poll admins can debug the poll." {
+		t.Errorf("debug of bob = %q, want it refused", reply)
+	}
+
+	reply := tp.run("room", "root", "!poll debug")
+	for _, want := range []string{
+		"Creator: alice",
+		"Flags: allow= winners=1 quiet=false",
+		` 2. "Tacos" alias="" url="" votes=1`,
+		"Votes: 1, voters: 1, ballots: 1",
+		"Voters are hidden as the poll is anonymous",
+	} {
+		if !strings.Contains(reply, want) {
+			t.Errorf("debug = %q, want it to contain %q", reply, want)
+		}
+	}
+	if strings.Contains(reply, "bob") {
+		t.Errorf("debug = %q, want the voter of the anonymous poll hidden", reply)
+	}
+
+	reply = tp.run("room", "root", "!poll debug -force")
+	if !strings.Contains(reply, "HasVoted: bob") || !strings.Contains(reply, " bob (bob) → 2") {
+		t.Errorf("forced debug = %q, want the vote map", reply)
+	}
+}
@@ -0,0 +1,71 @@
+package poll
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// decisionTemplate is the default of the decide.template pref.
+const decisionTemplate = "Decision: {winner} (carried {for}-{against}) on {date}"
+
+// pollDecide formats the winner of the last poll of the room that ended, or
+// of the ended poll with the code, as a decision record to post or log.
+// The decide.template pref lays the record out.
+func (pl *Poller) pollDecide(roomId, code string) string {
+	var poll pollEntry
+	var closedAt time.Time
+	if code != "" {
+		var err error
+		poll, closedAt, err = pl.findEnded(roomId, code)
+		if errors.Is(err, ErrNotFound) {
+			return fmt.Sprintf("There is no ended poll %s.", code)
+		}
+		if err != nil {
+			return fmt.Sprintf("Could not read the archived poll %s: %v", code, err)
+		}
+	} else {
+		c, ok := pl.lastClosed(roomId)
+		if !ok {
+			return "There is no ended poll to decide. Use !poll end to end the poll."
+		}
+		poll, closedAt = c.Poll, c.ClosedAt
+	}
+
+	leaders := poll.leaders()
+	if len(leaders) == 0 {
+		return fmt.Sprintf("No decision on %s, nobody voted.", poll.Title)
+	}
+	if len(leaders) > 1 {
+		return fmt.Sprintf("No decision on %s, %s.", poll.Title, poll.Winner())
+	}
+	winner := poll.Options[leaders[0]]
+	against := 0
+	for k, o := range poll.Options {
+		if k != leaders[0] {
+			against += o.Votes
+		}
+	}
+	return strings.NewReplacer(
+		"{title}", poll.Title,
+		"{winner}", winner.Text,
+		"{for}", strconv.Itoa(winner.Votes),
+		"{against}", strconv.Itoa(against),
+		"{date}", closedAt.Format("2006-01-02"),
+	).Replace(pl.pref(roomId, "decide.template", decisionTemplate))
+}
+
+// lastClosed returns the last poll of the room that ended.
+func (pl *Poller) lastClosed(roomId string) (closedPoll, bool) {
+	pl.mutex.RLock()
+	defer pl.mutex.RUnlock()
+
+	for k := len(pl.closedPolls) - 1; k >= 0; k-- {
+		if pl.closedPolls[k].RoomId == roomId {
+			return pl.closedPolls[k], true
+		}
+	}
+	return closedPoll{}, false
+}
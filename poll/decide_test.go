@@ -0,0 +1,62 @@
+package poll
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDecide(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.start("room", "", "Pizza", "Tacos", "Sushi")
+	tp.castVotes("room", 1, 2, 1, 3, 1)
+
+	if reply := tp.run("room", "alice", "!poll decide"); reply != "There is no ended poll to decide. Use !poll end to end the poll." {
+		t.Errorf("decide before the end = %q", reply)
+	}
+	tp.run("room", "alice", "!poll end")
+	date := tp.clock.Now().Format("2006-01-02")
+	if reply, want := tp.run("room", "alice", "!poll decide"), "Decision: Pizza (carried 3-2) on "+date; reply != want {
+		t.Errorf("decide = %q, want %q", reply, want)
+	}
+
+	tp.prefs["room/decide.template"] = "{title} {winner} {for}:{against}"
+	if reply, want := tp.run("room", "alice", "!poll decide"), "Lunch? Pizza 3:2"; reply != want {
+		t.Errorf("decide with a template = %q, want %q", reply, want)
+	}
+}
+
+func TestDecideTie(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.start("room", "", "Pizza", "Tacos")
+	tp.run("room", "alice", "!poll end")
+	if reply := tp.run("room", "alice", "!poll decide"); reply != "No decision on Lunch?, nobody voted." {
+		t.Errorf("decide without votes = %q", reply)
+	}
+
+	tp.start("room", "", "Pizza", "Tacos")
+	tp.castVotes("room", 1, 2)
+	tp.run("room", "alice", "!poll end")
+	if reply := tp.run("room", "alice", "!poll decide"); !strings.HasPrefix(reply, "No decision on Lunch?, ") {
+		t.Errorf("decide of a tie = %q", reply)
+	}
+}
+
+func TestDecideByCode(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.start("room", "", "Pizza", "Tacos")
+	tp.castVotes("room", 2)
+	tp.run("room", "alice", "!poll end")
+	first, _ := tp.lastClosed("room")
+
+	tp.start("room", "", "Pizza", "Tacos")
+	tp.castVotes("room", 1)
+	tp.run("room", "alice", "!poll end")
+
+	date := tp.clock.Now().Format("2006-01-02")
+	if reply, want := tp.run("room", "alice", "!poll decide "+first.Poll.Code), "Decision: Tacos (carried 1-0) on "+date; reply != want {
+		t.Errorf("decide %s = %q, want %q", first.Poll.Code, reply, want)
+	}
+	if reply := tp.run("room", "alice", "!poll decide zz9"); reply != "There is no ended poll zz9." {
+		t.Errorf("decide of an unknown code = %q", reply)
+	}
+}
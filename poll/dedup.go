@@ -0,0 +1,49 @@
+package poll
+
+import (
+	"container/list"
+	"sync"
+)
+
+// recentEventsSize is the number of event ids remembered to drop commands
+// delivered more than once by the broker.
+const recentEventsSize = 1024
+
+// eventLRU is a bounded set of recently handled event ids, evicting the least
+// recently seen id first.
+type eventLRU struct {
+	mutex sync.Mutex
+	size  int
+	order *list.List
+	index map[string]*list.Element
+}
+
+func newEventLRU(size int) *eventLRU {
+	return &eventLRU{
+		size:  size,
+		order: list.New(),
+		index: make(map[string]*list.Element),
+	}
+}
+
+// Seen records the id and reports whether it was already recorded. A nil
+// eventLRU, that of a zero Poller, records nothing.
+func (l *eventLRU) Seen(id string) bool {
+	if l == nil {
+		return false
+	}
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if e, ok := l.index[id]; ok {
+		l.order.MoveToFront(e)
+		return true
+	}
+	l.index[id] = l.order.PushFront(id)
+	if l.order.Len() > l.size {
+		oldest := l.order.Back()
+		l.order.Remove(oldest)
+		delete(l.index, oldest.Value.(string))
+	}
+	return false
+}
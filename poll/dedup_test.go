@@ -0,0 +1,49 @@
+package poll
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/netflix/hal-9001/hal"
+)
+
+func TestReplayedEvent(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.prefs["room/vote.cooldown"] = "0s"
+	tp.start("room", "-lockvotes=1h", "Pizza", "Tacos")
+
+	evt := hal.Evt{ID: "evt-1", Body: "!poll vote 1", RoomId: "room", User: "bob", UserId: "bob", Broker: tp.broker}
+	tp.poll(evt)
+	n := tp.broker.count()
+	tp.poll(evt)
+	if msgs := tp.broker.since(n); len(msgs) > 0 {
+		t.Errorf("replies to the replayed event = %+v, want none", msgs)
+	}
+	if got, want := tp.votes("room"), []int{1, 0}; !reflect.DeepEqual(got, want) {
+		t.Errorf("votes = %v, want %v", got, want)
+	}
+
+	// the same id in another room is another event
+	tp.start("other", "", "Pizza", "Tacos")
+	evt.RoomId = "other"
+	tp.poll(evt)
+	if got, want := tp.votes("other"), []int{1, 0}; !reflect.DeepEqual(got, want) {
+		t.Errorf("votes in the other room = %v, want %v", got, want)
+	}
+}
+
+func TestEventLRUEvicts(t *testing.T) {
+	l := newEventLRU(2)
+	for _, id := range []string{"a", "b", "a", "c"} {
+		l.Seen(id)
+	}
+	// b was seen least recently and evicted by c
+	for _, tt := range []struct {
+		id   string
+		want bool
+	}{{"a", true}, {"c", true}, {"b", false}} {
+		if got := l.Seen(tt.id); got != tt.want {
+			t.Errorf("Seen(%s) = %v, want %v", tt.id, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package poll
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// delegations returns the delegations of the room, the delegate of each
+// delegator, as set with !poll delegate.
+func (pl *Poller) delegations(roomId string) map[string]string {
+	delegations := make(map[string]string)
+	for _, pair := range strings.Split(pl.pref(roomId, "delegates", ""), ",") {
+		from, to, ok := strings.Cut(pair, "=")
+		if from, to = normalizeUser(from), normalizeUser(to); ok && from != "" && to != "" {
+			delegations[from] = to
+		}
+	}
+	return delegations
+}
+
+// pollDelegate delegates the vote of a user to another in the polls of the
+// room, or withdraws the delegation if to is empty. Users delegate their own
+// vote, poll admins that of anybody.
+func (pl *Poller) pollDelegate(roomId, userId, from, to string) string {
+	if userId != from && !pl.isAdmin(roomId, userId) {
+		return "Only poll admins can delegate the vote of other users."
+	}
+	if from == to {
+		return "A user cannot delegate their vote to themselves."
+	}
+
+	// the pref is read and written back without holding the mutex, as
+	// writing the prefs takes a call to the database
+	pl.delegatesMutex.Lock()
+	defer pl.delegatesMutex.Unlock()
+
+	delegations := pl.delegations(roomId)
+	if to == "" {
+		if _, ok := delegations[from]; !ok {
+			return fmt.Sprintf("%s has not delegated their vote.", from)
+		}
+		delete(delegations, from)
+	} else {
+		if _, ok := delegations[to]; ok {
+			return fmt.Sprintf("%s has delegated their vote, votes are delegated a single time.", to)
+		}
+		for _, delegate := range delegations {
+			if delegate == from {
+				return fmt.Sprintf("%s is a delegate, votes are delegated a single time.", from)
+			}
+		}
+		delegations[from] = to
+	}
+
+	pairs := make([]string, 0, len(delegations))
+	for f, t := range delegations {
+		pairs = append(pairs, fmt.Sprintf("%s=%s", f, t))
+	}
+	sort.Strings(pairs)
+	if err := setPref(pl.name, roomId, "delegates", strings.Join(pairs, ",")); err != nil {
+		pl.log().Warn("setting the delegates pref failed", "room", roomId, "err", err)
+		return "Could not change the setting, please try again later."
+	}
+	if to == "" {
+		return fmt.Sprintf("%s votes for themselves again.", from)
+	}
+	return fmt.Sprintf("%s votes on behalf of %s from now on.", to, from)
+}
+
+// castDelegated casts the vote of the delegate for the option at index of
+// the poll the delegate just voted in, on behalf of the users who delegated
+// their vote to the delegate and haven't voted yet, and returns them. The
+// vote of the delegate may have ended the poll, e.g. with -autoclose, in
+// which case no vote is cast. It must be called with the mutex held.
+func (pl *Poller) castDelegated(roomId string, poll *pollEntry, userId, userName string, index int) []string {
+	var delegators []string
+	for from, to := range pl.delegations(roomId) {
+		if to == userId || to == userName {
+			delegators = append(delegators, from)
+		}
+	}
+	sort.Strings(delegators)
+
+	var cast []string
+	for _, from := range delegators {
+		if pl.polls[roomId] != poll || !poll.IsActive {
+			break
+		}
+		if poll.voted(from) {
+			continue
+		}
+		if _, err := pl.voteFor(roomId, userId, from, index); err != nil {
+			pl.log().Debug("casting a delegated vote failed", "room", roomId, "user", from, "err", err)
+			continue
+		}
+		cast = append(cast, from)
+	}
+	return cast
+}
+
+// voted reports whether the user voted in the poll.
+func (p pollEntry) voted(userId string) bool {
+	for _, uId := range p.HasVoted {
+		if uId == userId {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,81 @@
+package poll
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDelegate(t *testing.T) {
+	tp := newTestPoller(t)
+	for _, from := range []string{"carol", "dave", "erin"} {
+		if reply := tp.run("room", from, "!poll delegate "+from+" bob"); reply != "bob votes on behalf of "+from+" from now on." {
+			t.Errorf("delegate = %q", reply)
+		}
+	}
+	tp.start("room", "", "Pizza", "Tacos")
+	tp.run("room", "erin", "!poll vote 1")
+
+	reply := tp.run("room", "bob", "!poll vote 2")
+	if !strings.HasSuffix(reply, "\nAlso voted on behalf of carol, dave.") {
+		t.Errorf("vote of the delegate = %q, want the delegators who haven't voted", reply)
+	}
+	if got, want := tp.votes("room"), []int{1, 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("votes = %v, want %v", got, want)
+	}
+
+	tp.mutex.RLock()
+	defer tp.mutex.RUnlock()
+	var audited []string
+	for _, e := range tp.polls["room"].Audit {
+		if strings.Contains(e.Action, "on behalf of") {
+			audited = append(audited, e.Action)
+		}
+	}
+	want := []string{"voted for option 2 on behalf of carol", "voted for option 2 on behalf of dave"}
+	if !reflect.DeepEqual(audited, want) {
+		t.Errorf("audit = %q, want %q", audited, want)
+	}
+}
+
+func TestDelegateOnce(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.run("room", "carol", "!poll delegate carol bob")
+	if reply := tp.run("room", "bob", "!poll delegate bob dave"); reply != "bob is a delegate, votes are delegated a single time." {
+		t.Errorf("delegate by a delegate = %q", reply)
+	}
+	if reply := tp.run("room", "dave", "!poll delegate dave carol"); reply != "carol has delegated their vote, votes are delegated a single time." {
+		t.Errorf("delegate to a delegator = %q", reply)
+	}
+	if reply := tp.run("room", "bob", "!poll delegate bob bob"); reply != "A user cannot delegate their vote to themselves." {
+		t.Errorf("delegate to oneself = %q", reply)
+	}
+	if reply := tp.run("room", "carol", "!poll delegate carol"); reply != "carol votes for themselves again." {
+		t.Errorf("withdrawn delegation = %q", reply)
+	}
+	if got := tp.prefs["room/delegates"]; got != "" {
+		t.Errorf("delegates pref = %q, want it empty", got)
+	}
+}
+
+func TestDelegatedNotCastInTheNextPoll(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.via = memberBroker{tp.broker, map[string][]string{"room": {"alice", "bob"}}}
+	tp.run("room", "carol", "!poll delegate carol bob")
+	tp.start("room", "-autoclose", "Pizza", "Tacos")
+	tp.run("room", "alice", `!poll chain {"title": "Dessert?", "options": ["Cake", "Fruit"]}`)
+	tp.run("room", "alice", "!poll vote 1")
+
+	// bob's vote closes the poll and starts the next one, which carol's vote
+	// must not go to
+	if reply := tp.run("room", "bob", "!poll vote 2"); strings.Contains(reply, "on behalf of") {
+		t.Errorf("vote closing the poll = %q, want no delegated vote", reply)
+	}
+	if got, want := tp.votes("room"), []int{0, 0}; !reflect.DeepEqual(got, want) {
+		t.Errorf("votes of the next poll = %v, want %v", got, want)
+	}
+	c, ok := tp.lastClosed("room")
+	if !ok || c.Poll.voted("carol") || c.Poll.Options[1].Votes != 1 {
+		t.Errorf("closed poll = %+v, want the delegated vote left out", c.Poll)
+	}
+}
@@ -0,0 +1,63 @@
+package poll
+
+import (
+	"fmt"
+	"strings"
+)
+
+// pollDraft shows the options of a poll yet to start along with the
+// commands to edit them, for the creator preparing the poll.
+func (pl *Poller) pollDraft(roomId string) string {
+	pl.mutex.RLock()
+	defer pl.mutex.RUnlock()
+
+	poll, ok := pl.polls[roomId]
+	if !ok {
+		return "There is no poll."
+	}
+	if poll.IsActive {
+		return "The poll is running, use !poll show to see it."
+	}
+
+	lines := []string{fmt.Sprintf("Draft of %s:", poll.viewedBy("").Title)}
+	if len(poll.Options) == 0 {
+		lines = append(lines, " No options yet.")
+	}
+	for k, o := range poll.Options {
+		lines = append(lines, fmt.Sprintf(" %d. %s", k+1, o.label()))
+	}
+	lines = append(lines,
+		"Add an option with !poll option <option>, remove one with !poll unoption <index>,",
+		"reorder them with !poll move <from> <to>, then start the poll with !poll start.")
+	return strings.Join(lines, "\n")
+}
+
+// pollUnoption removes the option at index from a poll yet to start.
+func (pl *Poller) pollUnoption(roomId, userId string, index int) string {
+	pl.mutex.Lock()
+	defer pl.unlock()
+
+	poll, ok := pl.polls[roomId]
+	if !ok {
+		return "There is no poll."
+	}
+	if poll.IsActive {
+		return "Options cannot be removed while the poll is running."
+	}
+	if !pl.canManage(roomId, userId, poll) {
+		return "Only the creator of the poll or a poll admin can remove options."
+	}
+	if index <= 0 || index > len(poll.Options) {
+		return fmt.Sprintf("Please choose a number between 1 to %d", len(poll.Options))
+	}
+
+	op := poll.Options[index-1]
+	poll.Options = append(poll.Options[:index-1], poll.Options[index:]...)
+	switch {
+	case poll.Answer == index:
+		poll.Answer = 0
+	case poll.Answer > index:
+		poll.Answer--
+	}
+	return fmt.Sprintf("Removed option: %s", op.Text)
+}
@@ -0,0 +1,38 @@
+package poll
+
+import "testing"
+
+func TestDraft(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.run("room", "alice", "!poll new Lunch?")
+	want := "Draft of Lunch?:\n" +
+		" No options yet.\n" +
+		"Add an option with !poll option <option>, remove one with !poll unoption <index>,\n" +
+		"reorder them with !poll move <from> <to>, then start the poll with !poll start."
+	if reply := tp.run("room", "alice", "!poll draft"); reply != want {
+		t.Errorf("draft without options = %q, want %q", reply, want)
+	}
+
+	tp.run("room", "alice", "!poll option Pizza")
+	tp.run("room", "alice", "!poll option Tacos")
+	tp.run("room", "alice", "!poll option Sushi")
+	if reply := tp.run("room", "alice", "!poll unoption 2"); reply != "Removed option: Tacos" {
+		t.Errorf("unoption = %q", reply)
+	}
+	want = "Draft of Lunch?:\n" +
+		" 1. Pizza\n" +
+		" 2. Sushi\n" +
+		"Add an option with !poll option <option>, remove one with !poll unoption <index>,\n" +
+		"reorder them with !poll move <from> <to>, then start the poll with !poll start."
+	if reply := tp.run("room", "alice", "!poll draft"); reply != want {
+		t.Errorf("draft = %q, want %q", reply, want)
+	}
+
+	tp.run("room", "alice", "!poll start")
+	if reply := tp.run("room", "alice", "!poll draft"); reply != "The poll is running, use !poll show to see it." {
+		t.Errorf("draft of a running poll = %q", reply)
+	}
+	if reply := tp.run("room", "alice", "!poll unoption 1"); reply != "Options cannot be removed while the poll is running." {
+		t.Errorf("unoption of a running poll = %q", reply)
+	}
+}
@@ -0,0 +1,176 @@
+package poll
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// dumpVersion is the version of the format written by DumpAll.
+const dumpVersion = 1
+
+// dump is the document written by DumpAll, the polls keyed by room id.
+type dump struct {
+	Version int                   `json:"version"`
+	Polls   map[string]*pollEntry `json:"polls"`
+}
+
+// DumpAll serializes the polls of every room as JSON, e.g. for a backup or
+// a migration. The dump can be restored with LoadAll.
+func DumpAll() ([]byte, error) {
+	return defaultPoller.DumpAll()
+}
+
+// DumpAll serializes the polls of the instance, see DumpAll.
+func (pl *Poller) DumpAll() ([]byte, error) {
+	pl.mutex.RLock()
+	defer pl.mutex.RUnlock()
+
+	return json.Marshal(dump{Version: dumpVersion, Polls: pl.polls})
+}
+
+// LoadAll restores the polls of a dump written by DumpAll. It returns an
+// error wrapping ErrInvalidDocument for a malformed dump, or ErrStateExists
+// when there already are polls, which ReplaceAll overwrites instead.
+func LoadAll(data []byte) error {
+	return defaultPoller.LoadAll(data)
+}
+
+// LoadAll restores the polls of the instance, see LoadAll.
+func (pl *Poller) LoadAll(data []byte) error {
+	return pl.loadAll(data, false)
+}
+
+// ReplaceAll restores the polls of a dump written by DumpAll, discarding
+// the current ones.
+func ReplaceAll(data []byte) error {
+	return defaultPoller.ReplaceAll(data)
+}
+
+// ReplaceAll restores the polls of the instance, see ReplaceAll.
+func (pl *Poller) ReplaceAll(data []byte) error {
+	return pl.loadAll(data, true)
+}
+
+func (pl *Poller) loadAll(data []byte, force bool) error {
+	var d dump
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&d); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidDocument, err)
+	}
+	if d.Version != dumpVersion {
+		return fmt.Errorf("%w: unsupported version %d", ErrInvalidDocument, d.Version)
+	}
+	for roomId, poll := range d.Polls {
+		if err := poll.validate(pl.maxVotes(roomId)); err != nil {
+			return fmt.Errorf("room %s: %w", roomId, err)
+		}
+	}
+
+	pl.mutex.Lock()
+	defer pl.unlock()
+
+	if len(pl.polls) > 0 && !force {
+		return fmt.Errorf("%w: %d rooms have a poll", ErrStateExists, len(pl.polls))
+	}
+
+	for _, poll := range pl.polls {
+		poll.stopTimers()
+	}
+	pl.polls = make(map[string]*pollEntry, len(d.Polls))
+	pl.federations = nil
+	for roomId, poll := range d.Polls {
+		pl.polls[roomId] = poll
+		if poll.IsActive && !poll.Deadline.IsZero() {
+			pl.scheduleClose(roomId, poll)
+		}
+		if poll.IsActive {
+			pl.armSnapshots(roomId, poll)
+		}
+		if poll.Federation != "" {
+			if pl.federations == nil {
+				pl.federations = make(map[string]string)
+			}
+			pl.federations[poll.Federation] = roomId
+		}
+	}
+	return nil
+}
+
+// maxVotes returns the most votes, or points, an option of a poll restored
+// in the room may have, as set by the room's votes.max pref. Zero disables
+// the limit.
+func (pl *Poller) maxVotes(roomId string) int {
+	limit, err := strconv.Atoi(pl.pref(roomId, "votes.max", "1000000"))
+	if err != nil || limit < 0 {
+		return 1000000
+	}
+	return limit
+}
+
+// validate checks a poll restored from a dump, whose votes must add up to
+// its voters unless they are allocated points, and stay at most limit per
+// option unless limit is zero.
+func (p *pollEntry) validate(limit int) error {
+	if p == nil {
+		return fmt.Errorf("%w: poll is null", ErrInvalidDocument)
+	}
+	if p.Title == "" {
+		return fmt.Errorf("%w: title is required", ErrInvalidDocument)
+	}
+	votes := 0
+	for k, o := range p.Options {
+		if o.Text == "" {
+			return fmt.Errorf("%w: option %d is empty", ErrInvalidDocument, k+1)
+		}
+		if o.Votes < 0 {
+			return fmt.Errorf("%w: option %d has negative votes", ErrInvalidDocument, k+1)
+		}
+		if limit > 0 && o.Votes > limit {
+			return fmt.Errorf("%w: option %d has more than %d votes", ErrInvalidDocument, k+1, limit)
+		}
+		votes += o.Votes
+	}
+	if p.Budget == 0 && votes != len(p.HasVoted) {
+		return fmt.Errorf("%w: %d votes for %d voters", ErrInvalidDocument, votes, len(p.HasVoted))
+	}
+	if p.Answer < 0 || p.Answer > len(p.Options) {
+		return fmt.Errorf("%w: answer %d is out of range", ErrInvalidDocument, p.Answer)
+	}
+	for _, b := range p.Ballots {
+		if b.Option < 0 || b.Option >= len(p.Options) {
+			return fmt.Errorf("%w: ballot of %s is out of range", ErrInvalidDocument, b.UserId)
+		}
+	}
+	for _, r := range p.Timeline {
+		if r.Option < 0 || r.Option >= len(p.Options) {
+			return fmt.Errorf("%w: vote at %s is out of range", ErrInvalidDocument, r.Time)
+		}
+	}
+	return nil
+}
+
+// checkTally logs a warning when the votes of the options don't match the
+// ballots of the voters, which holds after every vote, revotes included. It
+// must be called with the mutex held.
+func (pl *Poller) checkTally(roomId string, poll *pollEntry) {
+	if poll.Budget > 0 {
+		return
+	}
+	counts := make([]int, len(poll.Options))
+	for _, b := range poll.Ballots {
+		if b.Option >= 0 && b.Option < len(counts) {
+			counts[b.Option]++
+		}
+	}
+	for k, o := range poll.Options {
+		if o.Votes != counts[k] {
+			pl.log().Warn("tally does not match the ballots", "room", roomId, "option", k+1, "votes", o.Votes, "ballots", counts[k])
+		}
+	}
+	if len(poll.Ballots) != len(poll.HasVoted) {
+		pl.log().Warn("ballots do not match the voters", "room", roomId, "ballots", len(poll.Ballots), "voters", len(poll.HasVoted))
+	}
+}
@@ -0,0 +1,128 @@
+package poll
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDumpRoundTrip(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.start("room", "", "Pizza", "Tacos")
+	tp.run("room", "bob", "!poll vote 2")
+	tp.start("other", "-quiz", "Soup", "Salad", "Stew")
+	tp.run("other", "alice", "!poll answer 3")
+	tp.run("other", "carol", "!poll vote 3")
+	data, err := tp.DumpAll()
+	if err != nil {
+		t.Fatalf("DumpAll failed: %v", err)
+	}
+
+	restored := newTestPoller(t)
+	if err := restored.LoadAll(data); err != nil {
+		t.Fatalf("LoadAll failed: %v", err)
+	}
+	if got, want := restored.votes("room"), []int{0, 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("restored votes of room = %v, want %v", got, want)
+	}
+	if got, want := restored.votes("other"), []int{0, 0, 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("restored votes of other = %v, want %v", got, want)
+	}
+	if err := restored.Vote("room", "bob", 1); !errors.Is(err, ErrAlreadyVoted) {
+		t.Errorf("Vote of a restored voter = %v, want ErrAlreadyVoted", err)
+	}
+	if reply := restored.run("other", "alice", "!poll end"); !strings.HasSuffix(reply, "Correct answer: Stew\n1 of 1 voters got it right: carol") {
+		t.Errorf("end of the restored quiz = %q, want its answer kept", reply)
+	}
+}
+
+func TestLoadAllKeepsCurrentPolls(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.start("room", "", "Pizza", "Tacos")
+	data := []byte(`{"version": 1, "polls": {"other": {"Title": "Dinner?", "Options": [{"Text": "Soup"}]}}}`)
+
+	if err := tp.LoadAll(data); !errors.Is(err, ErrStateExists) {
+		t.Errorf("LoadAll over a poll = %v, want ErrStateExists", err)
+	}
+	if !tp.hasPoll("room") {
+		t.Fatal("LoadAll dropped the current poll")
+	}
+	if err := tp.ReplaceAll(data); err != nil {
+		t.Fatalf("ReplaceAll failed: %v", err)
+	}
+	if tp.hasPoll("room") || !tp.hasPoll("other") {
+		t.Error("ReplaceAll didn't replace the current polls")
+	}
+}
+
+func TestReplaceAllStopsTimers(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.start("room", "-duration=10m", "Pizza", "Tacos")
+	if err := tp.ReplaceAll([]byte(`{"version": 1, "polls": {}}`)); err != nil {
+		t.Fatalf("ReplaceAll failed: %v", err)
+	}
+
+	n := tp.broker.count()
+	tp.clock.Advance(time.Hour)
+	if msgs := tp.broker.since(n); len(msgs) > 0 {
+		t.Errorf("messages after replacing the polls = %+v, want none", msgs)
+	}
+}
+
+func TestLoadAllInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"not json", `{`},
+		{"unknown field", `{"version": 1, "polls": {}, "extra": true}`},
+		{"unsupported version", `{"version": 2, "polls": {}}`},
+		{"null poll", `{"version": 1, "polls": {"room": null}}`},
+		{"no title", `{"version": 1, "polls": {"room": {"Options": [{"Text": "Pizza"}]}}}`},
+		{"empty option", `{"version": 1, "polls": {"room": {"Title": "Lunch?", "Options": [{"Text": ""}]}}}`},
+		{"negative votes", `{"version": 1, "polls": {"room": {"Title": "Lunch?", "Options": [{"Text": "Pizza", "Votes": -1}]}}}`},
+		{"votes without voters", `{"version": 1, "polls": {"room": {"Title": "Lunch?", "Options": [{"Text": "Pizza", "Votes": 2}]}}}`},
+		{"answer out of range", `{"version": 1, "polls": {"room": {"Title": "Lunch?", "Options": [{"Text": "Pizza"}], "Answer": 2}}}`},
+		{"ballot out of range", `{"version": 1, "polls": {"room": {"Title": "Lunch?", "Options": [{"Text": "Pizza", "Votes": 1}], "HasVoted": ["bob"], "Ballots": [{"UserId": "bob", "Option": 1}]}}}`},
+		{"timeline out of range", `{"version": 1, "polls": {"room": {"Title": "Lunch?", "Options": [{"Text": "Pizza"}], "Timeline": [{"Option": 3}]}}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tp := newTestPoller(t)
+			if err := tp.ReplaceAll([]byte(tt.data)); !errors.Is(err, ErrInvalidDocument) {
+				t.Errorf("ReplaceAll = %v, want ErrInvalidDocument", err)
+			}
+		})
+	}
+}
+
+func TestLoadAllVotesMax(t *testing.T) {
+	tests := []struct {
+		name  string
+		max   string // the votes.max pref of the room, if set
+		data  string
+		valid bool
+	}{
+		{"over the default max", "", `{"version": 1, "polls": {"room": {"Title": "Lunch?", "Budget": 10, "Options": [{"Text": "Pizza", "Votes": 1000001}]}}}`, false},
+		{"over votes.max", "10", `{"version": 1, "polls": {"room": {"Title": "Lunch?", "Budget": 100, "Options": [{"Text": "Pizza", "Votes": 11}]}}}`, false},
+		{"at votes.max", "10", `{"version": 1, "polls": {"room": {"Title": "Lunch?", "Budget": 100, "Options": [{"Text": "Pizza", "Votes": 10}]}}}`, true},
+		{"votes.max disabled", "0", `{"version": 1, "polls": {"room": {"Title": "Lunch?", "Budget": 10, "Options": [{"Text": "Pizza", "Votes": 5000000}]}}}`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tp := newTestPoller(t)
+			if tt.max != "" {
+				tp.prefs["room/votes.max"] = tt.max
+			}
+			err := tp.LoadAll([]byte(tt.data))
+			if tt.valid && err != nil {
+				t.Errorf("LoadAll failed: %v", err)
+			}
+			if !tt.valid && !errors.Is(err, ErrInvalidDocument) {
+				t.Errorf("LoadAll = %v, want ErrInvalidDocument", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package poll
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/netflix/hal-9001/hal"
+)
+
+// messageSender is implemented by brokers able to tell the id of a message
+// they sent.
+type messageSender interface {
+	// SendMessage sends the body of the event to its room and returns the
+	// id of the message.
+	SendMessage(evt hal.Evt) (string, error)
+}
+
+// messageEditor is implemented by brokers able to edit a message they sent.
+type messageEditor interface {
+	messageSender
+	// EditMessage replaces the message with the body of the event.
+	EditMessage(evt hal.Evt, messageId string) error
+}
+
+// replyNew replies to the creation of a poll. Where the broker tells the id
+// of the reply, it is remembered as the poll message, counting the reactions
+// of polls created with -reactions. Where the broker can also edit it, the
+// poll message is kept up to date with the results on every vote instead of
+// posting them anew.
+func (pl *Poller) replyNew(evt hal.Evt, msg string) {
+	sender, ok := evt.Broker.(messageSender)
+	if !ok {
+		pl.reply(evt, msg)
+		return
+	}
+	out := evt
+	out.Body = truncate(msg, pl.maxReplyLength(evt))
+	messageId, err := sender.SendMessage(out)
+	if err != nil {
+		pl.log().Warn("sending the poll message failed", "room", evt.RoomId, "err", err)
+		pl.reply(evt, msg)
+		return
+	}
+
+	pl.mutex.Lock()
+	defer pl.unlock()
+
+	if poll, ok := pl.polls[evt.RoomId]; ok && poll.MessageId == "" && poll.origin.ID == evt.ID && poll.Creator == evt.UserId {
+		poll.MessageId = messageId
+	}
+}
+
+// refreshMessage edits the poll message of the room to show the current
+// results. When the edit fails, the results are replied instead.
+func (pl *Poller) refreshMessage(evt hal.Evt) {
+	editor, ok := evt.Broker.(messageEditor)
+	if !ok {
+		return
+	}
+
+	pl.mutex.RLock()
+	poll, ok := pl.polls[evt.RoomId]
+	if !ok || poll.MessageId == "" || poll.HideUntilVote {
+		pl.mutex.RUnlock()
+		return
+	}
+	messageId, results := poll.MessageId, poll.messageBody()
+	pl.mutex.RUnlock()
+
+	out := evt
+	out.Body = truncate(results, pl.maxReplyLength(evt))
+	if err := editor.EditMessage(out, messageId); err != nil {
+		pl.log().Warn("editing the poll message failed", "room", evt.RoomId, "err", err)
+		pl.replyVote(evt, results)
+	}
+}
+
+// editsMessage reports whether the poll message is kept up to date with the
+// results, which it isn't for polls hiding them until users vote.
+func (p pollEntry) editsMessage() bool {
+	_, ok := p.origin.Broker.(messageEditor)
+	return ok && p.MessageId != "" && !p.HideUntilVote
+}
+
+// countdownEvery is how often the countdown of the poll message is updated.
+const countdownEvery = time.Minute
+
+// messageBody renders the poll message: the results, followed by the time
+// left for polls with a deadline.
+func (p pollEntry) messageBody() string {
+	body := fmt.Sprintf("Poll:\n%s", p.viewedBy("").Result())
+	if p.IsActive && !p.Deadline.IsZero() {
+		body = fmt.Sprintf("%s\nCloses in %s", body, formatDuration(p.Deadline.Sub(now())))
+	}
+	return body
+}
+
+// armCountdown updates the countdown of the poll message every minute until
+// the poll ends, where the broker can edit the message. It must be called
+// with the mutex held.
+func (pl *Poller) armCountdown(roomId string, poll *pollEntry) {
+	if poll.Deadline.IsZero() || !poll.editsMessage() {
+		return
+	}
+	editor := poll.origin.Broker.(messageEditor)
+	poll.countdown = afterFunc(countdownEvery, func() {
+		pl.mutex.Lock()
+		if current, ok := pl.polls[roomId]; !ok || current != poll || !poll.IsActive {
+			pl.unlock()
+			return
+		}
+		out, messageId := poll.origin, poll.MessageId
+		out.Body = truncate(poll.messageBody(), pl.maxReplyLength(out))
+		pl.armCountdown(roomId, poll)
+		pl.unlock()
+
+		if err := editor.EditMessage(out, messageId); err != nil {
+			pl.log().Warn("updating the countdown failed", "room", roomId, "err", err)
+		}
+	})
+}
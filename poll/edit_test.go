@@ -0,0 +1,108 @@
+package poll
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/netflix/hal-9001/hal"
+)
+
+// editBroker is a fakeBroker telling the ids of the messages it sends and
+// recording the edits of the messages.
+type editBroker struct {
+	*fakeBroker
+	mutex    sync.Mutex
+	messages int
+	edits    []string
+}
+
+func (b *editBroker) SendMessage(evt hal.Evt) (string, error) {
+	b.Send(evt)
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.messages++
+	return fmt.Sprintf("m%d", b.messages), nil
+}
+
+func (b *editBroker) EditMessage(evt hal.Evt, messageId string) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.edits = append(b.edits, messageId+": "+evt.Body)
+	return nil
+}
+
+func (b *editBroker) editsSince(n int) []string {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return append([]string(nil), b.edits[n:]...)
+}
+
+func TestVoteEditsPollMessage(t *testing.T) {
+	tp := newTestPoller(t)
+	broker := &editBroker{fakeBroker: tp.broker}
+	tp.via = broker
+	tp.start("room", "", "Pizza", "Tacos")
+
+	if reply := tp.run("room", "bob", "!poll vote 2"); reply != "Vote recorded for Tacos" {
+		t.Errorf("vote reply = %q, want the results left to the poll message", reply)
+	}
+	want := []string{"m1: Poll:\nLunch?\n 1. Pizza (0 votes)\n 2. Tacos (1 votes)"}
+	if got := broker.editsSince(0); !reflect.DeepEqual(got, want) {
+		t.Errorf("edits = %q, want %q", got, want)
+	}
+}
+
+func TestCountdown(t *testing.T) {
+	tp := newTestPoller(t)
+	broker := &editBroker{fakeBroker: tp.broker}
+	tp.via = broker
+	tp.start("room", "-duration=10m", "Pizza", "Tacos")
+
+	tp.clock.Advance(time.Minute)
+	want := []string{"m1: Poll:\nLunch?\n 1. Pizza (0 votes)\n 2. Tacos (0 votes)\nCloses in 9m0s"}
+	if got := broker.editsSince(0); !reflect.DeepEqual(got, want) {
+		t.Errorf("edits = %q, want %q", got, want)
+	}
+}
+
+func TestCountdownStopsAtTheEnd(t *testing.T) {
+	tp := newTestPoller(t)
+	broker := &editBroker{fakeBroker: tp.broker}
+	tp.via = broker
+	tp.start("room", "-duration=10m", "Pizza", "Tacos")
+
+	tp.clock.Advance(time.Minute)
+	tp.clock.Advance(time.Minute)
+	want := []string{
+		"m1: Poll:\nLunch?\n 1. Pizza (0 votes)\n 2. Tacos (0 votes)\nCloses in 9m0s",
+		"m1: Poll:\nLunch?\n 1. Pizza (0 votes)\n 2. Tacos (0 votes)\nCloses in 8m0s",
+	}
+	if got := broker.editsSince(0); !reflect.DeepEqual(got, want) {
+		t.Errorf("edits = %q, want %q", got, want)
+	}
+
+	tp.run("room", "alice", "!poll end")
+	n := len(broker.editsSince(0))
+	tp.clock.Advance(5 * time.Minute)
+	if got := broker.editsSince(n); len(got) > 0 {
+		t.Errorf("edits after the end = %q, want the countdown stopped", got)
+	}
+}
+
+func TestCountdownUnsupported(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.start("room", "-duration=10m", "Pizza", "Tacos")
+	n := tp.broker.count()
+	tp.clock.Advance(time.Minute)
+	if msgs := tp.broker.since(n); len(msgs) > 0 {
+		t.Errorf("messages = %+v, want no countdown without edits", msgs)
+	}
+	tp.mutex.RLock()
+	defer tp.mutex.RUnlock()
+	if tp.polls["room"].countdown != nil {
+		t.Error("countdown armed for a broker that cannot edit messages")
+	}
+}
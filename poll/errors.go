@@ -0,0 +1,99 @@
+package poll
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Errors returned by the exported functions of the package.
+var (
+	ErrNoPoll          = errors.New("poll: no poll")
+	ErrNotActive       = errors.New("poll: poll is not active")
+	ErrPollExists      = errors.New("poll: poll already exists")
+	ErrInvalidIndex    = errors.New("poll: invalid option index")
+	ErrAlreadyVoted    = errors.New("poll: already voted")
+	ErrVotesLocked     = errors.New("poll: votes are locked")
+	ErrNotEligible     = errors.New("poll: not eligible to vote")
+	ErrInvalidDocument = errors.New("poll: invalid poll document")
+	ErrStateExists     = errors.New("poll: polls already exist")
+	ErrNotPollMessage  = errors.New("poll: not the poll message")
+	ErrAckRequired     = errors.New("poll: terms not acknowledged")
+	ErrCooldown        = errors.New("poll: vote repeated within the cooldown")
+	ErrThrottled       = errors.New("poll: too many votes")
+	ErrBudgetPoll      = errors.New("poll: poll allocates points")
+	ErrNotBudgetPoll   = errors.New("poll: poll does not allocate points")
+	ErrNotAbstainer    = errors.New("poll: poll reopened for the abstainers")
+)
+
+// rangeError is an ErrInvalidIndex for the index of a poll with max options.
+type rangeError struct {
+	index, max int
+}
+
+func (e rangeError) Error() string {
+	if e.index <= 0 {
+		return fmt.Sprintf("%v: indices start at 1", ErrInvalidIndex)
+	}
+	return fmt.Sprintf("%v: not between 1 and %d", ErrInvalidIndex, e.max)
+}
+
+func (e rangeError) Is(target error) bool {
+	return target == ErrInvalidIndex
+}
+
+// tenureError is an ErrNotEligible for a user newer than the min tenure of
+// the poll.
+type tenureError struct {
+	min time.Duration
+}
+
+func (e tenureError) Error() string {
+	return fmt.Sprintf("%v: tenure below %s", ErrNotEligible, e.min)
+}
+
+func (e tenureError) Is(target error) bool {
+	return target == ErrNotEligible
+}
+
+// errorMessage translates an error of the package to a chat reply. Other
+// errors, such as those of vote validators, are replied as they are.
+func errorMessage(err error) string {
+	var re rangeError
+	var te tenureError
+	var ae ackError
+	switch {
+	case errors.As(err, &ae):
+		if ae.option == "" {
+			return fmt.Sprintf("This poll is binding: %s\nUse !poll ack to acknowledge it, then allocate your points again.", ae.terms)
+		}
+		return fmt.Sprintf("This poll is binding: %s\nUse !poll ack to acknowledge it and cast your vote for %s.", ae.terms, ae.option)
+	case errors.As(err, &re) && re.index <= 0:
+		return "Indices start at 1."
+	case errors.As(err, &re):
+		return fmt.Sprintf("Please choose a number between 1 to %d", re.max)
+	case errors.Is(err, ErrNoPoll):
+		return "There is no poll."
+	case errors.Is(err, ErrNotActive):
+		return "There is no active poll. Use !poll start to start the poll."
+	case errors.As(err, &te):
+		return fmt.Sprintf("You must have been around for at least %s to vote in this poll.", formatDuration(te.min))
+	case errors.Is(err, ErrNotEligible):
+		return "You are not eligible to vote in this poll."
+	case errors.Is(err, ErrAlreadyVoted):
+		return "You have already voted."
+	case errors.Is(err, ErrVotesLocked):
+		return "You have already voted, and votes can no longer be changed."
+	case errors.Is(err, ErrCooldown):
+		return "Vote ignored, you just voted."
+	case errors.Is(err, ErrThrottled):
+		return "Too many votes right now, please retry."
+	case errors.Is(err, ErrBudgetPoll):
+		return "This poll allocates points, use !poll allocate <index>=<points>... to vote."
+	case errors.Is(err, ErrNotBudgetPoll):
+		return "This poll doesn't allocate points, use !poll vote <index> to vote."
+	case errors.Is(err, ErrNotAbstainer):
+		return "The poll was reopened for the members who didn't vote, and you voted."
+	}
+	return err.Error()
+}
@@ -0,0 +1,79 @@
+package poll
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestVoteErrors(t *testing.T) {
+	tp := newTestPoller(t)
+	if err := tp.Vote("room", "bob", 1); !errors.Is(err, ErrNoPoll) {
+		t.Errorf("Vote without a poll = %v, want ErrNoPoll", err)
+	}
+	tp.run("room", "alice", "!poll new Lunch?")
+	if err := tp.Vote("room", "bob", 1); !errors.Is(err, ErrNotActive) {
+		t.Errorf("Vote in a draft = %v, want ErrNotActive", err)
+	}
+	tp.run("room", "alice", "!poll option Pizza")
+	tp.run("room", "alice", "!poll option Tacos")
+	tp.run("room", "alice", "!poll start")
+
+	if err := tp.Vote("room", "bob", 3); !errors.Is(err, ErrInvalidIndex) {
+		t.Errorf("Vote(3) = %v, want ErrInvalidIndex", err)
+	}
+	if err := tp.Vote("room", "bob", 1); err != nil {
+		t.Fatalf("Vote failed: %v", err)
+	}
+	if err := tp.Vote("room", "bob", 2); !errors.Is(err, ErrCooldown) {
+		t.Errorf("repeated Vote = %v, want ErrCooldown", err)
+	}
+	tp.clock.Advance(time.Minute)
+	if err := tp.Vote("room", "bob", 2); !errors.Is(err, ErrAlreadyVoted) {
+		t.Errorf("second Vote = %v, want ErrAlreadyVoted", err)
+	}
+}
+
+func TestErrorMessage(t *testing.T) {
+	for _, tt := range []struct {
+		err  error
+		want string
+	}{
+		{ErrNoPoll, "There is no poll."},
+		{ErrAlreadyVoted, "You have already voted."},
+		{rangeError{index: 3, max: 2}, "Please choose a number between 1 to 2"},
+		{errors.New("You must be on call to vote."), "You must be on call to vote."},
+	} {
+		if got := errorMessage(tt.err); got != tt.want {
+			t.Errorf("errorMessage(%v) = %q, want %q", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestVoteNonPositiveIndex(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.start("room", "", "Pizza", "Tacos")
+
+	for _, index := range []string{"0", "-1"} {
+		if reply := tp.run("room", "bob", "!poll vote "+index); reply != "Indices start at 1." {
+			t.Errorf("vote %s reply = %q, want the start-at-1 message", index, reply)
+		}
+	}
+	if reply := tp.run("room", "bob", "!poll vote 3"); reply != "Please choose a number between 1 to 2" {
+		t.Errorf("vote 3 reply = %q, want the range message", reply)
+	}
+	if got := tp.votes("room"); got[0]+got[1] != 0 {
+		t.Errorf("votes = %v, want none counted", got)
+	}
+}
+
+func TestVoteWithoutArgument(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.start("room", "", "Pizza", "Tacos")
+
+	for _, body := range []string{"!poll vote", "!poll vote   ", "!poll vote \t "} {
+		if reply := tp.run("room", "bob", body); reply != "Usage: !poll vote <index|alias|option>" {
+			t.Errorf("%q reply = %q, want the usage", body, reply)
+		}
+	}
+}
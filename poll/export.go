@@ -0,0 +1,52 @@
+package poll
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Export formats accepted by !poll export -format.
+const (
+	formatMonospace = "monospace"
+)
+
+// pollExport renders the results of the poll for pasting elsewhere.
+func (pl *Poller) pollExport(roomId, userId string, opts exportOptions) string {
+	pl.mutex.RLock()
+	defer pl.mutex.RUnlock()
+
+	poll, ok := pl.polls[roomId]
+	if !ok {
+		return "There is no poll."
+	}
+	if poll.hidesResults(userId) {
+		return poll.hiddenMessage()
+	}
+	return poll.viewedBy(userId).monospace()
+}
+
+// monospace renders the results as a code block with a column for the
+// indices, the options as typed and the votes, each padded to the widest of
+// its column, e.g.
+//
+//	Lunch?
+//	1. Pizza  4 votes
+//	2. Tacos 12 votes
+func (p pollEntry) monospace() string {
+	indexWidth, textWidth, votesWidth := 0, 0, 0
+	for k, o := range p.Options {
+		indexWidth = max(indexWidth, len(fmt.Sprint(k+1)))
+		textWidth = max(textWidth, utf8.RuneCountInString(o.typed()))
+		votesWidth = max(votesWidth, len(formatCount(o.Votes)))
+	}
+
+	lines := []string{"```", p.Title}
+	for k, o := range p.Options {
+		lines = append(lines, fmt.Sprintf("%*d. %s%s %*s %s", indexWidth, k+1,
+			o.typed(), strings.Repeat(" ", textWidth-utf8.RuneCountInString(o.typed())),
+			votesWidth, formatCount(o.Votes), p.unit()))
+	}
+	lines = append(lines, "```")
+	return strings.Join(lines, "\n")
+}
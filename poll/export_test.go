@@ -0,0 +1,42 @@
+package poll
+
+import "testing"
+
+func TestExportAligned(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.start("room", "", "Pizza", "Tacos al pastor", "Phở", "Ramen", "Sushi",
+		"Curry", "Salad", "Soup", "Burgers", "Kebab")
+	votes := make([]int, 0, 12)
+	for k := 0; k < 11; k++ {
+		votes = append(votes, 2)
+	}
+	tp.castVotes("room", append(votes, 10)...)
+
+	want := "```\n" +
+		"Lunch?\n" +
+		" 1. Pizza            0 votes\n" +
+		" 2. Tacos al pastor 11 votes\n" +
+		" 3. Phở              0 votes\n" +
+		" 4. Ramen            0 votes\n" +
+		" 5. Sushi            0 votes\n" +
+		" 6. Curry            0 votes\n" +
+		" 7. Salad            0 votes\n" +
+		" 8. Soup             0 votes\n" +
+		" 9. Burgers          0 votes\n" +
+		"10. Kebab            1 votes\n" +
+		"```"
+	if got := tp.run("room", "bob", "!poll export"); got != want {
+		t.Errorf("export =\n%s\nwant\n%s", got, want)
+	}
+	if got := tp.run("room", "bob", "!poll export -format=monospace"); got != want {
+		t.Errorf("export -format=monospace =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestExportHidden(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.start("room", "-hideuntilvote", "Pizza", "Tacos")
+	if got := tp.run("room", "bob", "!poll export"); got != hiddenResults {
+		t.Errorf("export before voting = %q, want %q", got, hiddenResults)
+	}
+}
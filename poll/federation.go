@@ -0,0 +1,98 @@
+package poll
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/netflix/hal-9001/hal"
+)
+
+// federatedCommands are the commands the members of the rooms of a
+// federation run against its poll. The other commands are only routed to
+// the poll for its creator and the poll admins of its room, and admin
+// commands always apply to the room itself.
+var federatedCommands = map[string]bool{
+	"show":  true,
+	"index": true,
+	"vote":  true,
+}
+
+// homeRoom returns the room keeping the poll the room of the event takes
+// part in with the command: the room itself, or the room of the federated
+// poll of its federation, as set by the federation pref. The room is then
+// remembered as a member of the federated poll, the results of which are
+// posted to it. It also returns why the user may not run the command
+// against the federated poll, if so.
+func (pl *Poller) homeRoom(evt hal.Evt, command string) (string, string) {
+	key := pl.pref(evt.RoomId, "federation", "")
+	if key == "" || adminCommands[command] {
+		return evt.RoomId, ""
+	}
+
+	pl.mutex.Lock()
+	defer pl.unlock()
+
+	if _, ok := pl.polls[evt.RoomId]; ok {
+		return evt.RoomId, ""
+	}
+	home, ok := pl.federations[key]
+	if !ok || home == evt.RoomId {
+		return evt.RoomId, ""
+	}
+	poll, ok := pl.polls[home]
+	if !ok || poll.Federation != key {
+		return evt.RoomId, ""
+	}
+	if poll.rooms == nil {
+		poll.rooms = make(map[string]hal.Evt)
+	}
+	poll.rooms[evt.RoomId] = evt
+	if !federatedCommands[command] && poll.verbIndex(command) == 0 && !pl.canManage(home, evt.UserId, poll) {
+		return home, fmt.Sprintf("Only the creator of the poll of the federation %s or a poll admin of its room can run !poll %s here.", key, command)
+	}
+	return home, ""
+}
+
+// federate makes the poll created with -federation the poll of the rooms of
+// the federation. It must be called with the mutex held.
+func (pl *Poller) federate(roomId, key string) error {
+	if pl.pref(roomId, "federation", "") != key {
+		return fmt.Errorf("This room is not in the federation %s.", key)
+	}
+	if home, ok := pl.federations[key]; ok && home != roomId {
+		if poll, ok := pl.polls[home]; ok && poll.Federation == key {
+			return fmt.Errorf("The federation %s already has the poll '%s'.", key, poll.viewedBy("").Title)
+		}
+	}
+	if pl.federations == nil {
+		pl.federations = make(map[string]string)
+	}
+	pl.federations[key] = roomId
+	return nil
+}
+
+// broadcastResults posts the results of a federated poll that ended to its
+// own room and the member rooms, but the room from, which replies with them.
+// It must be called with the mutex held.
+func (pl *Poller) broadcastResults(roomId string, poll *pollEntry, from, results string) {
+	if poll.Federation == "" {
+		return
+	}
+	if pl.federations[poll.Federation] == roomId {
+		delete(pl.federations, poll.Federation)
+	}
+	members := make([]hal.Evt, 0, len(poll.rooms))
+	for _, evt := range poll.rooms {
+		members = append(members, evt)
+	}
+	sort.Slice(members, func(i, j int) bool { return members[i].RoomId < members[j].RoomId })
+	for _, evt := range append([]hal.Evt{poll.origin}, members...) {
+		if evt.Broker == nil || evt.RoomId == from {
+			continue
+		}
+		evt := evt
+		pl.later(func() {
+			pl.replyResults(evt, results)
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package poll
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// federatedPoller returns a test poller whose rooms home, east and west are
+// in the federation eng, with the poll of home shared by the other two.
+func federatedPoller(t *testing.T) *testPoller {
+	tp := newTestPoller(t)
+	for _, room := range []string{"home", "east", "west"} {
+		tp.prefs[room+"/federation"] = "eng"
+	}
+	tp.start("home", "-federation=eng", "Pizza", "Tacos")
+	return tp
+}
+
+func TestFederatedVote(t *testing.T) {
+	tp := federatedPoller(t)
+
+	tp.run("east", "bob", "!poll vote 1")
+	tp.run("west", "carol", "!poll vote 2")
+	tp.clock.Advance(time.Minute)
+	if reply := tp.run("west", "bob", "!poll vote 2"); reply != "You have already voted." {
+		t.Errorf("vote from another member room reply = %q", reply)
+	}
+	if got, want := tp.votes("home"), []int{1, 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("votes = %v, want %v", got, want)
+	}
+	if tp.hasPoll("east") || tp.hasPoll("west") {
+		t.Error("a member room got a poll of its own")
+	}
+}
+
+func TestFederatedResults(t *testing.T) {
+	tp := federatedPoller(t)
+	tp.run("east", "bob", "!poll vote 1")
+	tp.run("west", "carol", "!poll vote 1")
+
+	n := tp.broker.count()
+	tp.run("east", "alice", "!poll end")
+	results := map[string]int{}
+	for _, m := range tp.broker.since(n) {
+		if !m.DM && strings.HasPrefix(m.Body, "Poll finished") {
+			results[m.RoomId]++
+		}
+	}
+	if want := map[string]int{"home": 1, "east": 1, "west": 1}; !reflect.DeepEqual(results, want) {
+		t.Errorf("results posted per room = %v, want %v", results, want)
+	}
+}
+
+func TestFederationMembersShareThePoll(t *testing.T) {
+	tp := federatedPoller(t)
+	tp.prefs["north/federation"] = "eng"
+
+	if reply := tp.run("north", "alice", "!poll new -federation=eng Dinner?"); !strings.HasPrefix(reply, "The poll 'Lunch?' (active, 0 votes) already exists.") {
+		t.Errorf("new poll in a member room reply = %q, want the shared poll", reply)
+	}
+	if reply := tp.run("south", "dave", "!poll new -federation=eng Dinner?"); reply != "This room is not in the federation eng." {
+		t.Errorf("federated poll outside the federation reply = %q", reply)
+	}
+}
+
+func TestFederationMembersCannotManageThePoll(t *testing.T) {
+	tp := federatedPoller(t)
+	tp.prefs["home/admins"] = "root"
+	tp.prefs["east/admins"] = "eve"
+
+	refused := "Only the creator of the poll of the federation eng or a poll admin of its room can run !poll %s here."
+	for _, body := range []string{"!poll end", "!poll remove", "!poll option Sushi", "!poll new Dinner?", "!poll extend 5m"} {
+		command := strings.Fields(body)[1]
+		if reply := tp.run("east", "eve", body); reply != fmt.Sprintf(refused, command) {
+			t.Errorf("%s by an admin of a member room = %q", body, reply)
+		}
+	}
+	if !tp.hasPoll("home") || tp.hasPoll("east") {
+		t.Fatal("a refused command changed the polls")
+	}
+
+	if reply := tp.run("east", "eve", "!poll index"); !strings.Contains(reply, "Pizza") {
+		t.Errorf("index in a member room = %q, want the options of the federated poll", reply)
+	}
+	if reply := tp.run("east", "root", "!poll option Sushi"); !strings.Contains(reply, "Sushi") {
+		t.Errorf("option by an admin of the poll's room = %q", reply)
+	}
+	if reply := tp.run("east", "eve", "!poll disable"); reply == fmt.Sprintf(refused, "disable") {
+		t.Errorf("disable by an admin of a member room = %q, want it for the room itself", reply)
+	}
+	if tp.prefs["east/disabled"] != "true" || tp.prefs["home/disabled"] == "true" {
+		t.Errorf("disabled = %q in east and %q in home, want east alone", tp.prefs["east/disabled"], tp.prefs["home/disabled"])
+	}
+}
+
+func TestFederatedVerbs(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.prefs["home/federation"] = "eng"
+	tp.prefs["east/federation"] = "eng"
+	tp.start("home", "-federation=eng -verbs=yes,no", "Pizza", "Tacos")
+
+	tp.run("east", "bob", "!poll no")
+	if got, want := tp.votes("home"), []int{0, 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("votes = %v, want %v", got, want)
+	}
+}
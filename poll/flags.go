@@ -0,0 +1,393 @@
+package poll
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/netflix/hal-9001/hal"
+)
+
+// newOptions holds the flags accepted by !poll new.
+type newOptions struct {
+	Allow         []string
+	Force         bool
+	Winners       int
+	Quiet         bool
+	Duration      time.Duration
+	MinOptions    int
+	Open          bool
+	TieBreak      string
+	Seed          *int64
+	Quiz          bool
+	Raffle        bool
+	LockVotes     time.Duration
+	MinTenure     time.Duration
+	AutoClose     bool
+	Verbs         []string
+	SecretTitle   bool
+	Budget        int
+	Reactions     bool
+	SnapshotEvery time.Duration
+	Ack           string
+	Quorum        int
+	QuorumPercent int
+	Pin           bool
+	Federation    string
+	AntiSnipe     time.Duration
+	HideUntilVote bool
+	HiddenMessage string
+
+	// Origin is the event that created the poll, used to message the
+	// creator later on.
+	Origin hal.Evt
+}
+
+// parseFlags splits the leading -name or -name=value arguments off args and
+// returns them along with the remaining arguments. A "--" argument ends the
+// flags, so that the remaining arguments may start with a hyphen. A value in
+// quotes, such as -ack="no refunds", may span several arguments and is
+// returned without its quotes.
+func parseFlags(args []string) (map[string]string, []string) {
+	flags := make(map[string]string)
+	for len(args) > 0 {
+		arg := args[0]
+		if arg == "--" {
+			return flags, args[1:]
+		}
+		if len(arg) < 2 || arg[0] != '-' {
+			break
+		}
+		name, value := arg[1:], ""
+		if i := strings.Index(name, "="); i >= 0 {
+			name, value = name[:i], name[i+1:]
+		}
+		value, args = unquote(value, args[1:])
+		flags[name] = value
+	}
+	return flags, args
+}
+
+// unquote strips the quotes of a quoted value, joining the following
+// arguments up to the one closing the quotes when the value was split at
+// its spaces. It returns the value and the arguments left after it. A value
+// whose quotes are never closed is returned as it is.
+func unquote(value string, args []string) (string, []string) {
+	if value == "" || (value[0] != '"' && value[0] != '\'') {
+		return value, args
+	}
+	quote := value[:1]
+	closed := func(s string) bool { return strings.HasSuffix(s, quote) }
+	if len(value) > 1 && closed(value) {
+		return value[1 : len(value)-1], args
+	}
+	for k, arg := range args {
+		if closed(arg) {
+			words := append([]string{value}, args[:k+1]...)
+			joined := strings.Join(words, " ")
+			return joined[1 : len(joined)-1], args[k+1:]
+		}
+	}
+	return value, args
+}
+
+// sortedNames returns the names of the flags in order, so that the flags are
+// parsed and their problems reported in the same order whatever the order of
+// the arguments.
+func sortedNames(flags map[string]string) []string {
+	names := make([]string, 0, len(flags))
+	for name := range flags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// hasVerb reports whether the verb is one of the -verbs already parsed.
+func (opts newOptions) hasVerb(verb string) bool {
+	for _, v := range opts.Verbs {
+		if v == verb {
+			return true
+		}
+	}
+	return false
+}
+
+// parseNewOptions parses the flags of !poll new and returns the options
+// along with the words of the title. The problems of all the flags are
+// reported together in the error.
+func parseNewOptions(args []string) (newOptions, []string, error) {
+	var opts newOptions
+	var problems []string
+	flags, rest := parseFlags(args)
+	for _, name := range sortedNames(flags) {
+		parse, ok := newFlags[name]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("Unknown option -%s.", name))
+			continue
+		}
+		if err := parse(&opts, flags[name]); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+	if len(problems) > 0 {
+		return opts, rest, errors.New(strings.Join(problems, " "))
+	}
+	return opts, rest, nil
+}
+
+// newFlag parses the value of a flag of !poll new into the options.
+type newFlag func(opts *newOptions, value string) error
+
+// newFlags are the flags of !poll new by name.
+var newFlags = map[string]newFlag{
+	"allow": func(opts *newOptions, value string) error {
+		for _, user := range strings.Split(value, ",") {
+			if user = normalizeUser(user); user != "" {
+				opts.Allow = append(opts.Allow, user)
+			}
+		}
+		if len(opts.Allow) == 0 {
+			return errors.New("-allow needs at least one user.")
+		}
+		return nil
+	},
+	"force":         switchFlag(func(opts *newOptions) { opts.Force = true }),
+	"open":          switchFlag(func(opts *newOptions) { opts.Open = true }),
+	"quiz":          switchFlag(func(opts *newOptions) { opts.Quiz = true }),
+	"raffle":        switchFlag(func(opts *newOptions) { opts.Raffle = true }),
+	"secrettitle":   switchFlag(func(opts *newOptions) { opts.SecretTitle = true }),
+	"hideuntilvote": switchFlag(func(opts *newOptions) { opts.HideUntilVote = true }),
+	"pin":           switchFlag(func(opts *newOptions) { opts.Pin = true }),
+	"reactions":     switchFlag(func(opts *newOptions) { opts.Reactions = true }),
+	"autoclose":     switchFlag(func(opts *newOptions) { opts.AutoClose = true }),
+	"quiet":         switchFlag(func(opts *newOptions) { opts.Quiet = true }),
+	"hiddenmsg": func(opts *newOptions, value string) error {
+		opts.HiddenMessage = strings.TrimSpace(value)
+		if opts.HiddenMessage == "" {
+			return errors.New("-hiddenmsg needs the text shown in place of the results.")
+		}
+		return nil
+	},
+	"verbs": func(opts *newOptions, value string) error {
+		for _, verb := range strings.Split(value, ",") {
+			// commands are typed in any case
+			verb = strings.ToLower(strings.TrimSpace(verb))
+			if verb == "" || strings.ContainsAny(verb, " \t") {
+				return errors.New("-verbs must be a comma-separated list of words.")
+			}
+			if _, err := strconv.Atoi(verb); err == nil {
+				return errors.New("A verb cannot be a number.")
+			}
+			if isCommand(verb) {
+				return fmt.Errorf("The verb %s is a command, please choose another verb.", verb)
+			}
+			if opts.hasVerb(verb) {
+				return fmt.Errorf("The verb %s is given twice.", verb)
+			}
+			opts.Verbs = append(opts.Verbs, verb)
+		}
+		return nil
+	},
+	"duration": func(opts *newOptions, value string) error {
+		d, err := time.ParseDuration(value)
+		if err != nil || d <= 0 {
+			return errors.New("-duration must be a positive duration such as 10m.")
+		}
+		opts.Duration = d
+		return nil
+	},
+	"ack": func(opts *newOptions, value string) error {
+		opts.Ack = strings.TrimSpace(value)
+		if opts.Ack == "" {
+			return errors.New("-ack needs the terms to acknowledge.")
+		}
+		return nil
+	},
+	"snapshot": func(opts *newOptions, value string) error {
+		d, err := time.ParseDuration(value)
+		if err != nil || d < time.Second {
+			return errors.New("-snapshot must be a duration of at least a second such as 1m.")
+		}
+		opts.SnapshotEvery = d
+		return nil
+	},
+	"federation": func(opts *newOptions, value string) error {
+		if value == "" {
+			return errors.New("-federation needs the key of the federation.")
+		}
+		opts.Federation = value
+		return nil
+	},
+	"antisnipe": func(opts *newOptions, value string) error {
+		d, err := time.ParseDuration(value)
+		if err != nil || d < time.Second {
+			return errors.New("-antisnipe must be a duration of at least a second such as 30s.")
+		}
+		opts.AntiSnipe = d
+		return nil
+	},
+	"lockvotes": func(opts *newOptions, value string) error {
+		d, err := time.ParseDuration(value)
+		if err != nil || d <= 0 {
+			return errors.New("-lockvotes must be a positive duration such as 5m.")
+		}
+		opts.LockVotes = d
+		return nil
+	},
+	"mintenure": func(opts *newOptions, value string) error {
+		d, err := time.ParseDuration(value)
+		if err != nil || d <= 0 {
+			return errors.New("-mintenure must be a positive duration such as 720h.")
+		}
+		opts.MinTenure = d
+		return nil
+	},
+	"min": func(opts *newOptions, value string) error {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 2 {
+			return errors.New("-min must be an integer of at least 2.")
+		}
+		opts.MinOptions = n
+		return nil
+	},
+	"tiebreak": func(opts *newOptions, value string) error {
+		if value != tieBreakRandom {
+			return fmt.Errorf("Unknown tie-break %s, use -tiebreak=random.", value)
+		}
+		opts.TieBreak = value
+		return nil
+	},
+	"seed": func(opts *newOptions, value string) error {
+		seed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return errors.New("-seed must be an integer.")
+		}
+		opts.Seed = &seed
+		return nil
+	},
+	"budget": func(opts *newOptions, value string) error {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			return errors.New("-budget must be a positive integer.")
+		}
+		opts.Budget = n
+		return nil
+	},
+	"quorum": func(opts *newOptions, value string) error {
+		if percent, ok := strings.CutSuffix(value, "%"); ok {
+			n, err := strconv.Atoi(percent)
+			if err != nil || n < 1 || n > 100 {
+				return errors.New("-quorum must be a percentage between 1% and 100%.")
+			}
+			opts.QuorumPercent = n
+			return nil
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			return errors.New("-quorum must be a positive integer or a percentage such as 60%.")
+		}
+		opts.Quorum = n
+		return nil
+	},
+	"winners": func(opts *newOptions, value string) error {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			return errors.New("-winners must be a positive integer.")
+		}
+		opts.Winners = n
+		return nil
+	},
+}
+
+// switchFlag returns the newFlag of a flag without a value, which sets the
+// options.
+func switchFlag(set func(opts *newOptions)) newFlag {
+	return func(opts *newOptions, _ string) error {
+		set(opts)
+		return nil
+	}
+}
+
+// showOptions holds the flags accepted by !poll show.
+type showOptions struct {
+	Style string
+	Lang  string
+}
+
+// parseShowOptions parses the flags of !poll show.
+func parseShowOptions(args []string) (showOptions, []string, error) {
+	var opts showOptions
+	flags, rest := parseFlags(args)
+	for _, name := range sortedNames(flags) {
+		value := flags[name]
+		switch name {
+		case "style":
+			if value != styleDefault && value != styleFraction {
+				return opts, rest, fmt.Errorf("Unknown style %s, use -style=fraction.", value)
+			}
+			opts.Style = value
+		case "lang":
+			if !knownLang(value) {
+				return opts, rest, fmt.Errorf("Unknown language %s, use one of %s.", value, langs())
+			}
+			opts.Lang = value
+		default:
+			return opts, rest, fmt.Errorf("Unknown option -%s.", name)
+		}
+	}
+	return opts, rest, nil
+}
+
+// exportOptions holds the flags accepted by !poll export.
+type exportOptions struct {
+	Format string
+}
+
+// parseExportOptions parses the flags of !poll export.
+func parseExportOptions(args []string) (exportOptions, []string, error) {
+	opts := exportOptions{Format: formatMonospace}
+	flags, rest := parseFlags(args)
+	for _, name := range sortedNames(flags) {
+		value := flags[name]
+		switch name {
+		case "format":
+			if value != formatMonospace {
+				return opts, rest, fmt.Errorf("Unknown format %s, use -format=monospace.", value)
+			}
+			opts.Format = value
+		default:
+			return opts, rest, fmt.Errorf("Unknown option -%s.", name)
+		}
+	}
+	return opts, rest, nil
+}
+
+// normalizeUser strips the mention decoration from a user reference such as
+// "@alice" or "<@U024BE7LH>".
+func normalizeUser(user string) string {
+	user = strings.TrimSpace(user)
+	user = strings.TrimPrefix(user, "<")
+	user = strings.TrimSuffix(user, ">")
+	return strings.TrimPrefix(user, "@")
+}
+
+// resolveUser returns the id of the user a reference such as "@alice" or
+// "<@U024BE7LH>" names, as looked up by the broker. A reference the broker
+// cannot resolve is kept as the handle, which the votes and the delegations
+// also match against the user names.
+func resolveUser(broker hal.Broker, user string) (id string) {
+	user = normalizeUser(user)
+	defer func() {
+		if r := recover(); r != nil || id == "" {
+			id = user
+		}
+	}()
+	if broker == nil || user == "" || broker.LooksLikeUserId(user) {
+		return user
+	}
+	return broker.UserNameToId(user)
+}
@@ -0,0 +1,122 @@
+package poll
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseNewOptionsQuotedValues(t *testing.T) {
+	tests := []struct {
+		name  string
+		args  []string
+		ack   string
+		msg   string
+		title string
+	}{
+		{
+			name:  "ack split at its spaces",
+			args:  strings.Fields(`-ack="no refunds after lunch" Lunch?`),
+			ack:   "no refunds after lunch",
+			title: "Lunch?",
+		},
+		{
+			name:  "hiddenmsg split at its spaces",
+			args:  strings.Fields(`-hiddenmsg='Results revealed at close' Lunch?`),
+			msg:   "Results revealed at close",
+			title: "Lunch?",
+		},
+		{
+			name:  "both, already joined by the splitter",
+			args:  []string{"-ack=no refunds", "-hiddenmsg=Results revealed at close", "Lunch", "today?"},
+			ack:   "no refunds",
+			msg:   "Results revealed at close",
+			title: "Lunch today?",
+		},
+		{
+			name:  "single quoted word",
+			args:  []string{`-ack="binding"`, "Lunch?"},
+			ack:   "binding",
+			title: "Lunch?",
+		},
+		{
+			name:  "quotes never closed",
+			args:  strings.Fields(`-ack="no refunds Lunch?`),
+			ack:   `"no`,
+			title: "refunds Lunch?",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, title, err := parseNewOptions(tt.args)
+			if err != nil {
+				t.Fatalf("parseNewOptions(%q) failed: %v", tt.args, err)
+			}
+			if opts.Ack != tt.ack {
+				t.Errorf("Ack = %q, want %q", opts.Ack, tt.ack)
+			}
+			if opts.HiddenMessage != tt.msg {
+				t.Errorf("HiddenMessage = %q, want %q", opts.HiddenMessage, tt.msg)
+			}
+			if got := strings.Join(title, " "); got != tt.title {
+				t.Errorf("title = %q, want %q", got, tt.title)
+			}
+		})
+	}
+}
+
+func TestParseNewOptionsEmptyQuotedValue(t *testing.T) {
+	for _, arg := range []string{`-ack=""`, `-hiddenmsg=''`} {
+		if _, _, err := parseNewOptions([]string{arg, "Lunch?"}); err == nil {
+			t.Errorf("parseNewOptions(%q) succeeded, want an error", arg)
+		}
+	}
+}
+
+func TestParseFlagsSeparator(t *testing.T) {
+	opts, rest, err := parseNewOptions(strings.Fields("-quiet -- -1 or +1?"))
+	if err != nil {
+		t.Fatalf("parseNewOptions failed: %v", err)
+	}
+	if !opts.Quiet || strings.Join(rest, " ") != "-1 or +1?" {
+		t.Errorf("parseNewOptions = %+v, %q, want -quiet and the title after --", opts, rest)
+	}
+	if _, rest, _ := parseNewOptions(strings.Fields("-- -- --force")); strings.Join(rest, " ") != "-- --force" {
+		t.Errorf("title after -- = %q, want the following arguments verbatim", rest)
+	}
+}
+
+func TestNewHyphenTitle(t *testing.T) {
+	tp := newTestPoller(t)
+	if reply := tp.run("room", "alice", "!poll new -- -1 or +1?"); !strings.Contains(reply, "-1 or +1?") {
+		t.Errorf("new = %q, want the title kept", reply)
+	}
+	tp.mutex.RLock()
+	defer tp.mutex.RUnlock()
+	if title := tp.polls["room"].Title; title != "-1 or +1?" {
+		t.Errorf("title = %q, want %q", title, "-1 or +1?")
+	}
+}
+
+func TestParseShowOptionsInOrder(t *testing.T) {
+	// the flags are parsed by name, whatever their order or that of the map
+	for k := 0; k < 20; k++ {
+		if _, _, err := parseShowOptions([]string{"-zoom", "-style=bars", "-lang=fr"}); err == nil || err.Error() != "Unknown language fr, use one of en, ja." {
+			t.Fatalf("parseShowOptions = %v, want the problem of -lang first", err)
+		}
+		if _, _, err := parseExportOptions([]string{"-zoom", "-format=csv"}); err == nil || err.Error() != "Unknown format csv, use -format=monospace." {
+			t.Fatalf("parseExportOptions = %v, want the problem of -format first", err)
+		}
+	}
+}
+
+func TestNewProblemsReportedTogether(t *testing.T) {
+	tp := newTestPoller(t)
+	reply := tp.run("room", "alice", "!poll new -winners=abc -quorum=-1 -zoom Lunch?")
+	want := "-quorum must be a positive integer or a percentage such as 60%. -winners must be a positive integer. Unknown option -zoom."
+	if reply != want {
+		t.Errorf("new = %q, want %q", reply, want)
+	}
+	if tp.hasPoll("room") {
+		t.Error("a poll was created despite the problems")
+	}
+}
@@ -0,0 +1,71 @@
+package poll
+
+import (
+	"sort"
+	"strings"
+)
+
+// langDefault is the language of the replies unless the lang pref of the
+// room says otherwise.
+const langDefault = "en"
+
+// translations maps the replies of !poll show, as written in English, to
+// their translation in each other supported language. Texts without a
+// translation, such as the options, are shown as they are.
+var translations = map[string]map[string]string{
+	"en": {},
+	"ja": {
+		"There is no poll.":          "投票はありません。",
+		"Poll%s:\n%s":                "投票%s:\n%s",
+		" (Inactive)":                " (未開始)",
+		"%s\nNeeds %d more options.": "%s\nあと%d個の選択肢が必要です。",
+		" %d. %s (%s votes)":         " %d. %s (%s票)",
+		" %d. %s (%s points)":        " %d. %s (%sポイント)",
+		" ...and %d more options":    " ...ほか%d個の選択肢",
+		": 0/0":                      ": 0/0票",
+		": %s/%s (%d%%)":             ": %s/%s票 (%d%%)",
+		" ← leading":                 " ← 首位",
+		" Total: no votes yet":       " 合計: まだ投票はありません",
+		" Total: %s votes":           " 合計: %s票",
+		"Turnout: %d of %d members":  "投票率: %d/%d人",
+		hiddenResults:                "結果を見るには先に投票してください。選択肢は !poll index で確認できます。",
+		truncatedMarker:              "(省略)",
+	},
+}
+
+// knownLang reports whether the replies can be shown in the language.
+func knownLang(lang string) bool {
+	_, ok := translations[lang]
+	return ok
+}
+
+// langs lists the supported languages, e.g. "en, ja".
+func langs() string {
+	names := make([]string, 0, len(translations))
+	for lang := range translations {
+		names = append(names, lang)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// tr returns the translation of the English text in the language, or the
+// text itself if there is none.
+func tr(lang, text string) string {
+	if t, ok := translations[lang][text]; ok {
+		return t
+	}
+	return text
+}
+
+// lang returns the language of the replies in the room, that of the lang
+// pref unless override names another one.
+func (pl *Poller) lang(roomId, override string) string {
+	if override != "" {
+		return override
+	}
+	if lang := pl.pref(roomId, "lang", langDefault); knownLang(lang) {
+		return lang
+	}
+	return langDefault
+}
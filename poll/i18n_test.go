@@ -0,0 +1,66 @@
+package poll
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestShowLang(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.via = memberBroker{tp.broker, map[string][]string{"room": {"alice", "bob", "carol"}}}
+	tp.start("room", "", "Pizza", "Tacos", "Sushi")
+	tp.run("room", "bob", "!poll vote 2")
+	tp.prefs["room/result.limit"] = "2"
+
+	want := "投票:\nLunch?\n 2. Tacos (1票)\n 1. Pizza (0票)\n ...ほか1個の選択肢\n投票率: 1/3人"
+	if reply := tp.run("room", "bob", "!poll show -lang=ja"); reply != want {
+		t.Errorf("show -lang=ja = %q, want %q", reply, want)
+	}
+	want = "投票:\nLunch?\n 1. Pizza: 0/1票 (0%)\n 2. Tacos: 1/1票 (100%) ← 首位\n 3. Sushi: 0/1票 (0%)\n 合計: 1票\n投票率: 1/3人"
+	if reply := tp.run("room", "bob", "!poll show -lang=ja -style=fraction"); reply != want {
+		t.Errorf("show -lang=ja -style=fraction = %q, want %q", reply, want)
+	}
+	// the room default is left in English
+	if reply := tp.run("room", "bob", "!poll show"); !strings.HasPrefix(reply, "Poll:\nLunch?\n 2. Tacos (1 votes)") {
+		t.Errorf("show = %q, want it in English", reply)
+	}
+}
+
+func TestShowLangPref(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.prefs["room/lang"] = "ja"
+	if reply := tp.run("room", "bob", "!poll show"); reply != "投票はありません。" {
+		t.Errorf("show = %q, want it in Japanese", reply)
+	}
+	tp.run("room", "alice", "!poll new Lunch?")
+	tp.run("room", "alice", "!poll option Pizza")
+	want := "投票 (未開始):\nLunch?\n 1. Pizza: 0/0票\n 合計: まだ投票はありません\nあと1個の選択肢が必要です。"
+	if reply := tp.run("room", "bob", "!poll show -style=fraction"); reply != want {
+		t.Errorf("show -style=fraction = %q, want %q", reply, want)
+	}
+	if reply := tp.run("room", "bob", "!poll show -lang=en"); !strings.HasPrefix(reply, "Poll (Inactive):") {
+		t.Errorf("show -lang=en = %q, want it in English", reply)
+	}
+	if reply := tp.run("room", "bob", "!poll show -lang=fr"); reply != "Unknown language fr, use one of en, ja." {
+		t.Errorf("show -lang=fr = %q", reply)
+	}
+}
+
+func TestShowLangHidden(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.start("room", "-hideuntilvote", "Pizza", "Tacos")
+	if reply := tp.run("room", "bob", "!poll show -lang=ja"); reply != tr("ja", hiddenResults) {
+		t.Errorf("show -lang=ja = %q, want the hidden results in Japanese", reply)
+	}
+}
+
+func TestShowLangTruncated(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.prefs["room/reply.maxlength"] = "40"
+	tp.start("room", "", "Pizza", "Tacos", "Sushi")
+
+	reply := tp.run("room", "bob", "!poll show -lang=ja")
+	if !strings.HasSuffix(reply, "\n(省略)") || len(reply) > 40 {
+		t.Errorf("show -lang=ja = %q, want it truncated with the Japanese marker", reply)
+	}
+}
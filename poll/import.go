@@ -0,0 +1,123 @@
+package poll
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// pollDocument is the JSON document accepted by ImportPoll, e.g.
+//
+//	{"title": "Lunch?", "description": "Friday team lunch", "options": ["Pizza", "Tacos"]}
+//
+// Next optionally lists the polls started one after the other once the
+// poll ends.
+type pollDocument struct {
+	Title       string         `json:"title"`
+	Description string         `json:"description,omitempty"`
+	Options     []string       `json:"options"`
+	Next        []pollDocument `json:"next,omitempty"`
+}
+
+// decodeDocument parses and validates a poll document.
+func decodeDocument(data []byte) (pollDocument, error) {
+	var doc pollDocument
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&doc); err != nil {
+		return doc, fmt.Errorf("%w: %v", ErrInvalidDocument, err)
+	}
+	if dec.More() {
+		return doc, fmt.Errorf("%w: unexpected data after the document", ErrInvalidDocument)
+	}
+	if err := doc.validate(); err != nil {
+		return doc, err
+	}
+	if len(doc.Next) > maxChain {
+		return doc, fmt.Errorf("%w: at most %d polls can follow a poll", ErrInvalidDocument, maxChain)
+	}
+	for k := range doc.Next {
+		if len(doc.Next[k].Next) > 0 {
+			return doc, fmt.Errorf("%w: next poll %d cannot have next polls of its own", ErrInvalidDocument, k+1)
+		}
+		if err := doc.Next[k].validate(); err != nil {
+			return doc, fmt.Errorf("next poll %d: %w", k+1, err)
+		}
+	}
+	return doc, nil
+}
+
+// validate checks the document, trimming its texts.
+func (doc *pollDocument) validate() error {
+	doc.Title = strings.TrimSpace(doc.Title)
+	if doc.Title == "" {
+		return fmt.Errorf("%w: title is required", ErrInvalidDocument)
+	}
+	doc.Description = strings.TrimSpace(doc.Description)
+	for k := range doc.Options {
+		doc.Options[k] = strings.TrimSpace(doc.Options[k])
+		if doc.Options[k] == "" {
+			return fmt.Errorf("%w: option %d is empty", ErrInvalidDocument, k+1)
+		}
+	}
+	return nil
+}
+
+// entry creates the poll described by the document. It must be called with
+// the mutex held.
+func (doc pollDocument) entry(userId string) *pollEntry {
+	poll := &pollEntry{
+		Title:       doc.Title,
+		Description: doc.Description,
+		Creator:     userId,
+		Next:        doc.Next,
+		Seed:        now().UnixNano(),
+	}
+	for _, text := range doc.Options {
+		poll.Options = append(poll.Options, pollOption{Text: text})
+	}
+	return poll
+}
+
+// ImportPoll creates a poll in the room from a JSON document with a title,
+// an optional description and a list of options. It returns an error
+// wrapping ErrInvalidDocument for a malformed document, or ErrPollExists
+// when the room already has a poll.
+func ImportPoll(roomId string, data []byte) error {
+	return defaultPoller.ImportPoll(roomId, data)
+}
+
+// ImportPoll creates a poll in the room of the instance, see ImportPoll.
+func (pl *Poller) ImportPoll(roomId string, data []byte) error {
+	return pl.importPoll(roomId, "", data)
+}
+
+func (pl *Poller) importPoll(roomId, userId string, data []byte) error {
+	doc, err := decodeDocument(data)
+	if err != nil {
+		return err
+	}
+
+	pl.mutex.Lock()
+	defer pl.unlock()
+
+	if poll, ok := pl.polls[roomId]; ok {
+		return fmt.Errorf("%w: %s", ErrPollExists, poll.viewedBy("").Title)
+	}
+
+	pl.addPoll(roomId, doc.entry(userId))
+	return nil
+}
+
+func (pl *Poller) pollImport(roomId, userId, data string) string {
+	err := pl.importPoll(roomId, userId, []byte(data))
+	if errors.Is(err, ErrPollExists) {
+		return "Could not import the poll, there is already a poll. Use !poll remove to remove it."
+	}
+	if err != nil {
+		return fmt.Sprintf("Could not import the poll: %s.", strings.TrimPrefix(err.Error(), "poll: "))
+	}
+	return pl.pollShow(roomId, userId, showOptions{})
+}
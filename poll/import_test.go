@@ -0,0 +1,47 @@
+package poll
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestImportPoll(t *testing.T) {
+	tp := newTestPoller(t)
+	if err := tp.ImportPoll("room", []byte(`{"title": " Lunch? ", "options": ["Pizza", " Tacos "]}`)); err != nil {
+		t.Fatalf("ImportPoll failed: %v", err)
+	}
+	if reply := tp.run("room", "alice", "!poll index"); reply != "Options of Lunch?, vote with !poll vote <index>:\n 1. Pizza\n 2. Tacos" {
+		t.Errorf("index of the imported poll = %q", reply)
+	}
+	if err := tp.ImportPoll("room", []byte(`{"title": "Dinner?", "options": ["Soup"]}`)); !errors.Is(err, ErrPollExists) {
+		t.Errorf("ImportPoll over a poll = %v, want ErrPollExists", err)
+	}
+
+	for _, data := range []string{
+		`{"title": "", "options": ["Pizza"]}`,
+		`{"title": "Lunch?", "options": ["Pizza", " "]}`,
+		`{"title": "Lunch?", "options": ["Pizza"], "votes": [1]}`,
+		`{"title": "Lunch?"} {"title": "Dinner?"}`,
+		`{"title": "Lunch?", "next": [{"title": "Dessert?", "next": [{"title": "Coffee?"}]}]}`,
+	} {
+		if err := tp.ImportPoll("other", []byte(data)); !errors.Is(err, ErrInvalidDocument) {
+			t.Errorf("ImportPoll(%s) = %v, want ErrInvalidDocument", data, err)
+		}
+	}
+}
+
+func TestImportCommand(t *testing.T) {
+	tp := newTestPoller(t)
+
+	if reply := tp.run("room", "alice", `!poll import {"title": "Lunch?", "options": ["Pizza", "Tacos"`); !strings.HasPrefix(reply, "Could not import the poll: ") {
+		t.Errorf("import of malformed JSON reply = %q", reply)
+	}
+	if tp.hasPoll("room") {
+		t.Fatal("malformed JSON created a poll")
+	}
+	reply := tp.run("room", "alice", `!poll import {"title": "Lunch?", "options": ["Pizza", "Tacos"]}`)
+	if want := "Poll (Inactive):\nLunch?\n 1. Pizza (0 votes)\n 2. Tacos (0 votes)"; reply != want {
+		t.Errorf("import reply = %q, want %q", reply, want)
+	}
+}
@@ -0,0 +1,63 @@
+package poll
+
+import (
+	"errors"
+	"log/slog"
+
+	"github.com/netflix/hal-9001/hal"
+)
+
+// SetLogger replaces the logger of the default instance, slog.Default() by
+// default.
+func SetLogger(l *slog.Logger) {
+	defaultPoller.SetLogger(l)
+}
+
+// SetLogger replaces the logger of the instance. Like Register, it must be
+// called before the instance is used.
+func (pl *Poller) SetLogger(l *slog.Logger) {
+	pl.logger = l
+}
+
+// log returns the logger of the instance, tagged with its name.
+func (pl *Poller) log() *slog.Logger {
+	l := pl.logger
+	if l == nil {
+		l = slog.Default()
+	}
+	return l.With("plugin", pl.name)
+}
+
+// logCommand logs a command at debug level. The user of a vote is left to
+// logVote, which only names the voters of open polls.
+func (pl *Poller) logCommand(evt hal.Evt, command string) {
+	if command == "vote" || pl.verbIndex(evt.RoomId, command) > 0 {
+		pl.log().Debug("command", "room", evt.RoomId, "command", command)
+		return
+	}
+	pl.log().Debug("command", "room", evt.RoomId, "user", evt.UserId, "command", command)
+}
+
+// logVote logs the outcome of a vote, at debug level when it is recorded and
+// at warn level when it is refused. It must be called with the mutex held.
+func (pl *Poller) logVote(roomId, userId string, poll *pollEntry, index int, err error) {
+	attrs := []interface{}{"room", roomId}
+	if index > 0 {
+		// allocations, logged with index 0, spread over several options
+		attrs = append(attrs, "option", index)
+	}
+	if poll == nil {
+		poll = pl.polls[roomId]
+	}
+	if poll != nil && poll.Open {
+		attrs = append(attrs, "user", userId)
+	}
+	switch {
+	case err == nil:
+		pl.log().Debug("vote recorded", attrs...)
+	case errors.Is(err, ErrCooldown):
+		pl.log().Debug("vote ignored", append(attrs, "reason", err.Error())...)
+	default:
+		pl.log().Warn("vote refused", append(attrs, "reason", err.Error())...)
+	}
+}
@@ -0,0 +1,81 @@
+package poll
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// logRecorder captures the records logged as JSON, one per line.
+type logRecorder struct {
+	mutex sync.Mutex
+	buf   bytes.Buffer
+}
+
+func (r *logRecorder) Write(p []byte) (int, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.buf.Write(p)
+}
+
+// records returns the records with the message, their time left out.
+func (r *logRecorder) records(t *testing.T, msg string) []map[string]interface{} {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	var records []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(r.buf.String()), "\n") {
+		var record map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("log line %q: %v", line, err)
+		}
+		if record["msg"] == msg {
+			delete(record, "time")
+			records = append(records, record)
+		}
+	}
+	return records
+}
+
+func newLogRecorder(tp *testPoller) *logRecorder {
+	r := &logRecorder{}
+	tp.SetLogger(slog.New(slog.NewJSONHandler(r, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	return r
+}
+
+func TestVoteLogged(t *testing.T) {
+	tp := newTestPoller(t)
+	logs := newLogRecorder(tp)
+	tp.start("room", "", "Pizza", "Tacos")
+	tp.run("room", "bob", "!poll vote 2")
+	tp.run("room", "carol", "!poll vote 3")
+
+	want := map[string]interface{}{"level": "DEBUG", "msg": "vote recorded", "plugin": "poll", "room": "room", "option": 2.0}
+	if got := logs.records(t, "vote recorded"); len(got) != 1 || !reflect.DeepEqual(got[0], want) {
+		t.Errorf("vote records = %v, want %v without the voter of the anonymous poll", got, want)
+	}
+	want = map[string]interface{}{"level": "WARN", "msg": "vote refused", "plugin": "poll", "room": "room", "option": 3.0, "reason": "poll: invalid option index: not between 1 and 2"}
+	if got := logs.records(t, "vote refused"); len(got) != 1 || !reflect.DeepEqual(got[0], want) {
+		t.Errorf("refusal records = %v, want %v", got, want)
+	}
+	for _, record := range logs.records(t, "command") {
+		if record["command"] == "vote" && record["user"] != nil {
+			t.Errorf("command record = %v, want the voter left out", record)
+		}
+	}
+}
+
+func TestOpenVoteLoggedWithUser(t *testing.T) {
+	tp := newTestPoller(t)
+	logs := newLogRecorder(tp)
+	tp.start("room", "-open", "Pizza", "Tacos")
+	tp.run("room", "bob", "!poll vote 1")
+
+	if got := logs.records(t, "vote recorded"); len(got) != 1 || got[0]["user"] != "bob" {
+		t.Errorf("vote records = %v, want the voter of the open poll", got)
+	}
+}
@@ -0,0 +1,48 @@
+package poll
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// closeMargin returns the margin between the two leading options below which
+// the result is flagged as close, as set by the room's close.margin pref,
+// either a number of votes such as "1" or a share of the votes such as "10%".
+// A zero margin disables the warning.
+func (pl *Poller) closeMargin(roomId string) (margin int, percent bool) {
+	value := strings.TrimSpace(pl.pref(roomId, "close.margin", "0"))
+	value, percent = strings.CutSuffix(value, "%")
+	margin, err := strconv.Atoi(value)
+	if err != nil || margin < 0 {
+		return 0, false
+	}
+	return margin, percent
+}
+
+// closeResult warns when the two leading options are within the margin of
+// the room, e.g. "Close result: Pizza 6, Tacos 5 — consider a runoff." It
+// returns an empty string otherwise.
+func (p pollEntry) closeResult(margin int, percent bool) string {
+	ranked := p.byVotes()
+	if margin == 0 || len(ranked) < 2 {
+		return ""
+	}
+	first, second := p.Options[ranked[0]], p.Options[ranked[1]]
+	if first.Votes == 0 {
+		return ""
+	}
+	diff := first.Votes - second.Votes
+	if percent {
+		total := 0
+		for _, o := range p.Options {
+			total += o.Votes
+		}
+		diff = diff * 100 / total
+	}
+	if diff > margin {
+		return ""
+	}
+	return fmt.Sprintf("Close result: %s %s, %s %s — consider a runoff.",
+		first.Text, formatCount(first.Votes), second.Text, formatCount(second.Votes))
+}
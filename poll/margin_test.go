@@ -0,0 +1,40 @@
+package poll
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCloseResult(t *testing.T) {
+	for _, tt := range []struct {
+		margin  string
+		a, b, c int
+		want    string
+	}{
+		{"1", 6, 5, 0, "Close result: Pizza 6, Tacos 5 — consider a runoff."},
+		{"1", 8, 2, 0, ""},
+		{"10%", 6, 5, 0, "Close result: Pizza 6, Tacos 5 — consider a runoff."},
+		{"10%", 8, 2, 0, ""},
+		{"10%", 3, 5, 2, ""},
+		{"0", 6, 5, 0, ""},
+		{"1", 0, 0, 0, ""},
+	} {
+		tp := newTestPoller(t)
+		tp.prefs["room/close.margin"] = tt.margin
+		p := pollEntry{Options: []pollOption{{Text: "Pizza", Votes: tt.a}, {Text: "Tacos", Votes: tt.b}, {Text: "Sushi", Votes: tt.c}}}
+		if got := p.closeResult(tp.closeMargin("room")); got != tt.want {
+			t.Errorf("closeResult of %d-%d-%d within %s = %q, want %q", tt.a, tt.b, tt.c, tt.margin, got, tt.want)
+		}
+	}
+}
+
+func TestEndFlagsCloseResult(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.prefs["room/close.margin"] = "1"
+	tp.start("room", "", "Pizza", "Tacos")
+	tp.castVotes("room", 1, 1, 1, 1, 1, 1, 2, 2, 2, 2, 2)
+
+	if reply := tp.run("room", "alice", "!poll end"); !strings.HasSuffix(reply, "\nClose result: Pizza 6, Tacos 5 — consider a runoff.") {
+		t.Errorf("end = %q, want the close result flagged", reply)
+	}
+}
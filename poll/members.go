@@ -0,0 +1,198 @@
+package poll
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/netflix/hal-9001/hal"
+)
+
+// memberLister is implemented by brokers able to list the current members
+// of a room.
+type memberLister interface {
+	RoomMembers(roomId string) ([]string, error)
+}
+
+// membersTTL is how long the members of a room listed by the broker are
+// relied on before they are listed again.
+const membersTTL = time.Minute
+
+// errMembersUnknown is returned for the members of a room that were never
+// listed, as the broker cannot list them or nothing asked yet.
+var errMembersUnknown = errors.New("poll: members unknown")
+
+// memberList is the members of a room as listed by the broker at a time, or
+// why they could not be.
+type memberList struct {
+	ids []string
+	err error
+	at  time.Time
+}
+
+// fetchMembers lists the members of the room through the broker of its poll,
+// or of the poll of the room that ended last, unless they were listed less
+// than membersTTL ago. Listing the members is a call to the broker, so it is
+// made before taking the mutex, by the commands and the timers that may look
+// at the members. It must be called without the mutex held.
+func (pl *Poller) fetchMembers(roomId string) {
+	pl.mutex.RLock()
+	broker := pl.roomBroker(roomId)
+	pl.mutex.RUnlock()
+
+	lister, ok := broker.(memberLister)
+	if !ok {
+		return
+	}
+	pl.membersMutex.Lock()
+	m, ok := pl.members[roomId]
+	pl.membersMutex.Unlock()
+	if ok && m.err == nil && now().Sub(m.at) < membersTTL {
+		return
+	}
+
+	ids, err := lister.RoomMembers(roomId)
+	if err != nil {
+		pl.log().Warn("listing the room members failed", "room", roomId, "err", err)
+	}
+	pl.membersMutex.Lock()
+	defer pl.membersMutex.Unlock()
+	if pl.members == nil {
+		pl.members = make(map[string]memberList)
+	}
+	pl.members[roomId] = memberList{ids: ids, err: err, at: now()}
+}
+
+// roomBroker returns the broker of the poll of the room, or of the poll of
+// the room that ended last, if any. It must be called with the mutex held.
+func (pl *Poller) roomBroker(roomId string) hal.Broker {
+	if poll, ok := pl.polls[roomId]; ok {
+		return poll.origin.Broker
+	}
+	for k := len(pl.closedPolls) - 1; k >= 0; k-- {
+		if c := pl.closedPolls[k]; c.RoomId == roomId {
+			return c.Poll.origin.Broker
+		}
+	}
+	return nil
+}
+
+// roomMembers returns the members of the room as last listed by
+// fetchMembers, for a poll created on broker. It returns errMembersUnknown
+// if the broker cannot list them. It may be called with the mutex held.
+func (pl *Poller) roomMembers(roomId string, broker hal.Broker) ([]string, error) {
+	if _, ok := broker.(memberLister); !ok {
+		return nil, errMembersUnknown
+	}
+	pl.membersMutex.Lock()
+	defer pl.membersMutex.Unlock()
+
+	m, ok := pl.members[roomId]
+	if !ok {
+		return nil, errMembersUnknown
+	}
+	return m.ids, m.err
+}
+
+// turnout counts the voters of the poll among the current members of the
+// room, so that voters who left the room are ignored. It returns false if
+// the members are unknown. It must be called with the mutex held.
+func (pl *Poller) turnout(roomId string, poll *pollEntry) (voted, members int, ok bool) {
+	ids, err := pl.roomMembers(roomId, poll.origin.Broker)
+	if err != nil {
+		return 0, 0, false
+	}
+	hasVoted := make(map[string]bool, len(poll.HasVoted))
+	for _, userId := range poll.HasVoted {
+		hasVoted[userId] = true
+	}
+	for _, userId := range ids {
+		if hasVoted[userId] {
+			voted++
+		}
+	}
+	return voted, len(ids), true
+}
+
+// allVoted reports whether every current member of the room voted in the
+// poll. It is false when the members are unknown. It must be called with the
+// mutex held.
+func (pl *Poller) allVoted(roomId string, poll *pollEntry) bool {
+	voted, members, ok := pl.turnout(roomId, poll)
+	return ok && members > 0 && voted == members
+}
+
+// turnoutLine describes the turnout of the poll in the language, e.g.
+// "Turnout: 3 of 5 members", or returns an empty string if the members are
+// unknown. It must be called with the mutex held.
+func (pl *Poller) turnoutLine(roomId string, poll *pollEntry, lang string) string {
+	voted, members, ok := pl.turnout(roomId, poll)
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf(tr(lang, "Turnout: %d of %d members"), voted, members)
+}
+
+// closeIfAllVoted ends a poll created with -autoclose once every current
+// member of the room voted, announcing the results in the room. It must be
+// called with the mutex held.
+func (pl *Poller) closeIfAllVoted(roomId string, poll *pollEntry) {
+	if !poll.AutoClose || !pl.allVoted(roomId, poll) {
+		return
+	}
+	results := pl.endPoll(roomId, poll, roomId)
+	origin := poll.origin
+	pl.later(func() {
+		pl.replyResults(origin, fmt.Sprintf("Everybody voted! %s", results))
+	})
+}
+
+// maxPending is the number of members listed by !poll pending.
+const maxPending = 20
+
+// pollPending lists the members of the room who haven't voted in the open
+// poll, for its organizers to chase. Anonymous polls keep who voted to
+// themselves.
+func (pl *Poller) pollPending(roomId, userId string) string {
+	pl.mutex.RLock()
+	defer pl.mutex.RUnlock()
+
+	poll, ok := pl.polls[roomId]
+	if !ok {
+		return "There is no poll."
+	}
+	if !pl.canManage(roomId, userId, poll) {
+		return "Only the creator of the poll or a poll admin can see who hasn't voted."
+	}
+	if !poll.Open {
+		return "Only polls created with -open tell who hasn't voted."
+	}
+	ids, err := pl.roomMembers(roomId, poll.origin.Broker)
+	if errors.Is(err, errMembersUnknown) {
+		return "The members of the room are unknown."
+	}
+	if err != nil {
+		return "Could not list the members of the room."
+	}
+
+	hasVoted := make(map[string]bool, len(poll.HasVoted))
+	for _, uId := range poll.HasVoted {
+		hasVoted[uId] = true
+	}
+	var pending []string
+	for _, uId := range ids {
+		if !hasVoted[uId] {
+			pending = append(pending, uId)
+		}
+	}
+	if len(pending) == 0 {
+		return "Everybody voted."
+	}
+	more := ""
+	if len(pending) > maxPending {
+		more = fmt.Sprintf(" ...and %d more", len(pending)-maxPending)
+		pending = pending[:maxPending]
+	}
+	return fmt.Sprintf("Not voted yet: %s%s", strings.Join(pending, ", "), more)
+}
@@ -0,0 +1,132 @@
+package poll
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAutoCloseIgnoresDepartedMembers(t *testing.T) {
+	tp := newTestPoller(t)
+	members := map[string][]string{"room": {"alice", "bob", "carol"}}
+	tp.via = memberBroker{tp.broker, members}
+	tp.start("room", "-autoclose", "Pizza", "Tacos")
+
+	tp.run("room", "alice", "!poll vote 1")
+	members["room"] = []string{"alice", "carol"} // bob left without voting
+	tp.clock.Advance(membersTTL)
+	if reply := tp.run("room", "carol", "!poll vote 2"); !strings.Contains(reply, "Everybody voted! Poll finished, final results:") {
+		t.Errorf("replies to the last vote = %q, want the poll closed", reply)
+	}
+	if tp.hasPoll("room") {
+		t.Error("the poll is still running once every member voted")
+	}
+}
+
+func TestTurnoutIgnoresDepartedVoters(t *testing.T) {
+	tp := newTestPoller(t)
+	members := map[string][]string{"room": {"alice", "bob", "carol", "dave"}}
+	tp.via = memberBroker{tp.broker, members}
+	tp.start("room", "", "Pizza", "Tacos")
+	tp.run("room", "bob", "!poll vote 1")
+	tp.run("room", "carol", "!poll vote 2")
+
+	members["room"] = []string{"alice", "carol", "dave"}
+	tp.clock.Advance(membersTTL)
+	if reply := tp.run("room", "alice", "!poll show"); !strings.HasSuffix(reply, "\nTurnout: 1 of 3 members") {
+		t.Errorf("show = %q, want the turnout among the current members", reply)
+	}
+}
+
+// countingBroker is a memberBroker counting the listings of the members and
+// recording whether the mutex of the poller was held meanwhile.
+type countingBroker struct {
+	memberBroker
+	tp     *testPoller
+	calls  *int
+	locked *bool
+}
+
+func (b countingBroker) RoomMembers(roomId string) ([]string, error) {
+	*b.calls++
+	if b.tp.mutex.TryLock() {
+		b.tp.mutex.Unlock()
+	} else {
+		*b.locked = true
+	}
+	return b.memberBroker.RoomMembers(roomId)
+}
+
+func TestMembersListedOutsideTheMutex(t *testing.T) {
+	tp := newTestPoller(t)
+	var calls int
+	var locked bool
+	tp.via = countingBroker{memberBroker{tp.broker, map[string][]string{"room": {"alice", "bob", "carol"}}}, tp, &calls, &locked}
+	tp.start("room", "-autoclose -quorum=50%", "Pizza", "Tacos")
+
+	tp.run("room", "alice", "!poll vote 1")
+	tp.run("room", "bob", "!poll vote 2")
+	tp.run("room", "bob", "!poll show")
+	if calls != 1 {
+		t.Errorf("members listed %d times within %s, want once", calls, membersTTL)
+	}
+	tp.clock.Advance(membersTTL)
+	tp.run("room", "carol", "!poll vote 2")
+	if calls != 2 {
+		t.Errorf("members listed %d times, want them listed again after %s", calls, membersTTL)
+	}
+	if locked {
+		t.Error("members listed with the mutex held")
+	}
+}
+
+func TestPending(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.via = memberBroker{tp.broker, map[string][]string{"room": {"alice", "bob", "carol"}}}
+	tp.start("room", "-open", "Pizza", "Tacos")
+	tp.run("room", "bob", "!poll vote 1")
+
+	if reply := tp.run("room", "alice", "!poll pending"); reply != "Not voted yet: alice, carol" {
+		t.Errorf("pending = %q", reply)
+	}
+	tp.via = nil
+	tp.start("other", "-open", "Pizza", "Tacos")
+	if reply := tp.run("other", "alice", "!poll pending"); reply != "The members of the room are unknown." {
+		t.Errorf("pending without the members = %q", reply)
+	}
+	if got := tp.votes("room"); !reflect.DeepEqual(got, []int{1, 0}) {
+		t.Errorf("votes = %v", got)
+	}
+}
+
+func TestPendingAnonymous(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.via = memberBroker{tp.broker, map[string][]string{"room": {"alice", "bob"}}}
+	tp.start("room", "", "Pizza", "Tacos")
+
+	if reply := tp.run("room", "alice", "!poll pending"); reply != "Only polls created with -open tell who hasn't voted." {
+		t.Errorf("pending of an anonymous poll = %q", reply)
+	}
+	tp.start("room", "-force -open", "Pizza", "Tacos")
+	if reply := tp.run("room", "bob", "!poll pending"); reply != "Only the creator of the poll or a poll admin can see who hasn't voted." {
+		t.Errorf("pending by a voter = %q", reply)
+	}
+}
+
+func TestPendingCapped(t *testing.T) {
+	tp := newTestPoller(t)
+	members := []string{"alice"}
+	for k := 0; k < maxPending+5; k++ {
+		members = append(members, fmt.Sprintf("user%02d", k))
+	}
+	tp.via = memberBroker{tp.broker, map[string][]string{"room": members}}
+	tp.start("room", "-open", "Pizza", "Tacos")
+	tp.run("room", "alice", "!poll vote 1")
+
+	reply := tp.run("room", "alice", "!poll pending")
+	want := fmt.Sprintf("Not voted yet: %s ...and 5 more", strings.Join(members[1:maxPending+1], ", "))
+	if reply != want {
+		t.Errorf("pending = %q, want %q", reply, want)
+	}
+}
@@ -0,0 +1,58 @@
+package poll
+
+import (
+	"fmt"
+	"sort"
+)
+
+func (pl *Poller) pollMetrics(roomId, userId string) string {
+	if !pl.isAdmin(roomId, userId) {
+		return "Only poll admins can see the metrics."
+	}
+
+	pl.mutex.RLock()
+	defer pl.mutex.RUnlock()
+
+	now := now()
+	year, month, day := now.Date()
+	active, drafts, today := 0, 0, 0
+	votesByRoom := make(map[string]int)
+	countToday := func(room string, timeline []voteRecord) {
+		for _, v := range timeline {
+			if y, m, d := v.Time.In(now.Location()).Date(); y == year && m == month && d == day {
+				today++
+				votesByRoom[room]++
+			}
+		}
+	}
+	for room, poll := range pl.polls {
+		if poll.IsActive {
+			active++
+		} else {
+			drafts++
+		}
+		countToday(room, poll.Timeline)
+	}
+	// the votes of the polls that ended today count too
+	for _, c := range pl.closedPolls {
+		countToday(c.RoomId, c.Poll.Timeline)
+	}
+
+	busiest := "none"
+	if len(votesByRoom) > 0 {
+		rooms := make([]string, 0, len(votesByRoom))
+		for room := range votesByRoom {
+			rooms = append(rooms, room)
+		}
+		sort.Slice(rooms, func(i, j int) bool {
+			if votesByRoom[rooms[i]] != votesByRoom[rooms[j]] {
+				return votesByRoom[rooms[i]] > votesByRoom[rooms[j]]
+			}
+			return rooms[i] < rooms[j]
+		})
+		busiest = fmt.Sprintf("%s (%d votes today)", rooms[0], votesByRoom[rooms[0]])
+	}
+
+	return fmt.Sprintf("Poll metrics:\n Active polls: %d\n Draft polls: %d\n Votes today: %d\n Busiest room: %s",
+		active, drafts, today, busiest)
+}
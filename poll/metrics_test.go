@@ -0,0 +1,30 @@
+package poll
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMetrics(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.prefs["a/admins"] = "root"
+	tp.start("old", "", "Pizza", "Tacos")
+	tp.castVotes("old", 1, 2, 2, 2)
+	tp.run("old", "alice", "!poll end")
+	tp.clock.Advance(24 * time.Hour)
+
+	tp.start("a", "", "Pizza", "Tacos")
+	tp.castVotes("a", 1, 2)
+	tp.start("b", "", "Pizza", "Tacos")
+	tp.castVotes("b", 1, 1, 2)
+	tp.run("b", "alice", "!poll end")
+	tp.run("c", "alice", "!poll new Dinner?")
+
+	if reply := tp.run("a", "bob", "!poll metrics"); reply != "Only poll admins can see the metrics." {
+		t.Errorf("metrics of a user reply = %q", reply)
+	}
+	want := "Poll metrics:\n Active polls: 1\n Draft polls: 1\n Votes today: 5\n Busiest room: b (3 votes today)"
+	if reply := tp.run("a", "root", "!poll metrics"); reply != want {
+		t.Errorf("metrics = %q, want %q", reply, want)
+	}
+}
@@ -0,0 +1,60 @@
+package poll
+
+import (
+	"fmt"
+	"strings"
+)
+
+// pollNote sets the private note of the creator on the option at index, or
+// clears it if text is empty.
+func (pl *Poller) pollNote(roomId, userId string, index int, text string) string {
+	pl.mutex.Lock()
+	defer pl.unlock()
+
+	poll, ok := pl.polls[roomId]
+	if !ok {
+		return "There is no poll."
+	}
+	if userId != poll.Creator {
+		return "Only the creator of the poll can add notes."
+	}
+	if index <= 0 || index > len(poll.Options) {
+		return fmt.Sprintf("Please choose a number between 1 to %d", len(poll.Options))
+	}
+
+	poll.Options[index-1].CreatorNote = text
+	if text == "" {
+		return fmt.Sprintf("Note removed from %s.", poll.Options[index-1].Text)
+	}
+	return fmt.Sprintf("Note added to %s, only you can see it with !poll details.", poll.Options[index-1].Text)
+}
+
+// pollDetails shows the poll with its description, followed by the notes on
+// the options when the user is the creator.
+func (pl *Poller) pollDetails(roomId, userId string) string {
+	pl.mutex.RLock()
+	defer pl.mutex.RUnlock()
+
+	poll, ok := pl.polls[roomId]
+	if !ok {
+		return "There is no poll."
+	}
+
+	if poll.hidesResults(userId) {
+		return poll.hiddenMessage()
+	}
+	details := poll.viewedBy(userId).Details()
+	if userId != poll.Creator {
+		return details
+	}
+	var notes []string
+	for k, o := range poll.Options {
+		if o.CreatorNote != "" {
+			notes = append(notes, fmt.Sprintf(" %d. %s: %s", k+1, o.Text, o.CreatorNote))
+		}
+	}
+	if len(notes) == 0 {
+		return details
+	}
+	return fmt.Sprintf("%s\nYour notes:\n%s", details, strings.Join(notes, "\n"))
+}
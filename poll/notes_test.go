@@ -0,0 +1,36 @@
+package poll
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNotesCreatorOnly(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.start("room", "", "Pizza", "Tacos")
+
+	if reply := tp.run("room", "bob", "!poll note 1 cheap"); reply != "Only the creator of the poll can add notes." {
+		t.Errorf("note of bob = %q, want it refused", reply)
+	}
+	tp.run("room", "alice", "!poll note 2 this vendor is over budget")
+
+	if reply := tp.run("room", "alice", "!poll details"); !strings.HasSuffix(reply, "\nYour notes:\n 2. Tacos: this vendor is over budget") {
+		t.Errorf("details of the creator = %q, want the note", reply)
+	}
+	for _, body := range []string{"!poll details", "!poll show", "!poll vote 2"} {
+		if reply := tp.run("room", "bob", body); strings.Contains(reply, "over budget") {
+			t.Errorf("%s of bob = %q, want the note left out", body, reply)
+		}
+	}
+}
+
+func TestNoteRepliedPrivately(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.start("room", "", "Pizza", "Tacos")
+
+	n := tp.broker.count()
+	tp.run("room", "alice", "!poll note 2 over budget")
+	if msgs := tp.broker.since(n); len(msgs) != 1 || !msgs[0].DM {
+		t.Errorf("messages = %+v, want a direct message on a broker without ephemeral messages", msgs)
+	}
+}
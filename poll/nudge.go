@@ -0,0 +1,57 @@
+package poll
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/netflix/hal-9001/hal"
+)
+
+// nudgeAfter returns how long a poll may stay a draft before its creator is
+// reminded to start it, as set by the room's nudge.after pref. Zero disables
+// the reminder.
+func (pl *Poller) nudgeAfter(roomId string) time.Duration {
+	d, err := time.ParseDuration(pl.pref(roomId, "nudge.after", "1h"))
+	if err != nil || d < 0 {
+		return 0
+	}
+	return d
+}
+
+// armNudge schedules the reminder sent to the creator of a poll that has not
+// been started yet. It must be called with the mutex held.
+func (pl *Poller) armNudge(roomId string, poll *pollEntry) {
+	d := pl.nudgeAfter(roomId)
+	if d == 0 || poll.origin.Broker == nil {
+		return
+	}
+	poll.nudge = afterFunc(d, func() {
+		pl.mutex.Lock()
+		current, ok := pl.polls[roomId]
+		if !ok || current != poll || poll.IsActive || poll.nudged {
+			pl.unlock()
+			return
+		}
+		poll.nudged = true
+		title, origin := poll.Title, poll.origin
+		pl.unlock()
+
+		if err := trySendDM(origin, fmt.Sprintf("Your poll '%s' is still a draft — start it with !poll start.", title)); err != nil {
+			pl.log().Warn("reminding of the draft poll failed", "room", roomId, "user", origin.UserId, "err", err)
+		}
+	})
+}
+
+// stopNudge cancels the pending draft reminder of the poll, if any.
+func (p *pollEntry) stopNudge() {
+	if p.nudge != nil {
+		p.nudge.Stop()
+		p.nudge = nil
+	}
+}
+
+// sendDM sends msg as a direct message to the user of evt.
+var sendDM = func(evt hal.Evt, msg string) {
+	evt.Body = msg
+	evt.Broker.SendDM(evt)
+}
@@ -0,0 +1,36 @@
+package poll
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNudgeDraft(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.prefs["room/nudge.after"] = "30m"
+	tp.run("room", "alice", "!poll new Lunch?")
+
+	n := tp.broker.count()
+	tp.clock.Advance(29 * time.Minute)
+	if msgs := tp.broker.since(n); len(msgs) > 0 {
+		t.Fatalf("messages before the nudge is due = %+v", msgs)
+	}
+	tp.clock.Advance(2 * time.Hour)
+	want := []sent{{RoomId: "room", UserId: "alice", DM: true, Body: "Your poll 'Lunch?' is still a draft — start it with !poll start."}}
+	if msgs := tp.broker.since(n); !reflect.DeepEqual(msgs, want) {
+		t.Errorf("messages = %+v, want a single nudge %+v", msgs, want)
+	}
+}
+
+func TestNudgeStartedInTime(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.prefs["room/nudge.after"] = "30m"
+	tp.start("room", "", "Pizza", "Tacos")
+
+	n := tp.broker.count()
+	tp.clock.Advance(time.Hour)
+	if msgs := tp.broker.since(n); len(msgs) > 0 {
+		t.Errorf("messages after starting in time = %+v, want no nudge", msgs)
+	}
+}
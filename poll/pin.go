@@ -0,0 +1,74 @@
+package poll
+
+import (
+	"github.com/netflix/hal-9001/hal"
+)
+
+// messagePinner is implemented by brokers able to pin a message of a room.
+type messagePinner interface {
+	// PinMessage pins the message to the room of the event.
+	PinMessage(evt hal.Evt, messageId string) error
+	// UnpinMessage unpins the message from the room of the event.
+	UnpinMessage(evt hal.Evt, messageId string) error
+}
+
+// pinMessage pins the poll message of a poll created with -pin, or unpins
+// it, where the broker tells the message and can pin it. The broker calls of
+// a room are queued and made in order, so that an unpin never overtakes the
+// pin before it. It must be called with the mutex held.
+func (pl *Poller) pinMessage(roomId string, poll *pollEntry, pin bool) {
+	if !poll.Pin || poll.MessageId == "" {
+		return
+	}
+	p, ok := poll.origin.Broker.(messagePinner)
+	if !ok {
+		return
+	}
+	origin, messageId := poll.origin, poll.MessageId
+	pl.queuePin(roomId, func() {
+		if pin {
+			if err := p.PinMessage(origin, messageId); err != nil {
+				pl.log().Warn("pinning the poll message failed", "room", roomId, "err", err)
+			}
+			return
+		}
+		if err := p.UnpinMessage(origin, messageId); err != nil {
+			pl.log().Warn("unpinning the poll message failed", "room", roomId, "err", err)
+		}
+	})
+}
+
+// queuePin adds the broker call f to the queue of the room, and starts
+// making the calls of the queue once the mutex is released when it was
+// empty. It must be called with the mutex held.
+func (pl *Poller) queuePin(roomId string, f func()) {
+	pl.pinMutex.Lock()
+	defer pl.pinMutex.Unlock()
+
+	if pl.pins == nil {
+		pl.pins = make(map[string][]func())
+	}
+	pl.pins[roomId] = append(pl.pins[roomId], f)
+	if len(pl.pins[roomId]) == 1 {
+		pl.later(func() { pl.drainPins(roomId, f) })
+	}
+}
+
+// drainPins makes the broker calls queued for the room one after the other,
+// starting with f, until the queue is empty.
+func (pl *Poller) drainPins(roomId string, f func()) {
+	for {
+		f()
+
+		pl.pinMutex.Lock()
+		queue := pl.pins[roomId][1:]
+		if len(queue) == 0 {
+			delete(pl.pins, roomId)
+			pl.pinMutex.Unlock()
+			return
+		}
+		pl.pins[roomId] = queue
+		f = queue[0]
+		pl.pinMutex.Unlock()
+	}
+}
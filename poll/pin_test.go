@@ -0,0 +1,100 @@
+package poll
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/netflix/hal-9001/hal"
+)
+
+// pinBroker is a fakeBroker telling the ids of the messages it sends and
+// recording the messages pinned and unpinned, and whether the mutex of the
+// poller was held meanwhile.
+type pinBroker struct {
+	*fakeBroker
+	tp       *testPoller
+	mutex    sync.Mutex
+	messages int
+	pins     []string
+	locked   bool
+}
+
+func (b *pinBroker) SendMessage(evt hal.Evt) (string, error) {
+	b.Send(evt)
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.messages++
+	return fmt.Sprintf("m%d", b.messages), nil
+}
+
+func (b *pinBroker) PinMessage(evt hal.Evt, messageId string) error {
+	b.record("pin " + messageId)
+	return nil
+}
+
+func (b *pinBroker) UnpinMessage(evt hal.Evt, messageId string) error {
+	b.record("unpin " + messageId)
+	return nil
+}
+
+func (b *pinBroker) record(call string) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if b.tp.mutex.TryLock() {
+		b.tp.mutex.Unlock()
+	} else {
+		b.locked = true
+	}
+	b.pins = append(b.pins, call)
+}
+
+func (b *pinBroker) calls() []string {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return append([]string(nil), b.pins...)
+}
+
+func TestPinInOrder(t *testing.T) {
+	tp := newTestPoller(t)
+	broker := &pinBroker{fakeBroker: tp.broker, tp: tp}
+	tp.via = broker
+	tp.start("room", "-pin", "Pizza", "Tacos")
+	if got, want := broker.calls(), []string{"pin m1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("pin calls after the start = %q, want %q", got, want)
+	}
+	tp.run("room", "alice", "!poll end")
+
+	if got, want := broker.calls(), []string{"pin m1", "unpin m1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("pin calls = %q, want %q", got, want)
+	}
+	if broker.locked {
+		t.Error("broker called while the mutex is held")
+	}
+}
+
+func TestUnpinReplacedAndRemovedPolls(t *testing.T) {
+	tp := newTestPoller(t)
+	broker := &pinBroker{fakeBroker: tp.broker, tp: tp}
+	tp.via = broker
+	tp.start("room", "-pin", "Pizza", "Tacos")
+	tp.run("room", "alice", "!poll new -force -pin Dinner?")
+	tp.run("room", "alice", "!poll option Soup")
+	tp.run("room", "alice", "!poll option Salad")
+	tp.run("room", "alice", "!poll start")
+	tp.run("room", "alice", "!poll remove")
+
+	want := []string{"pin m1", "unpin m1", "pin m2", "unpin m2"}
+	if got := broker.calls(); !reflect.DeepEqual(got, want) {
+		t.Errorf("pin calls = %q, want %q", got, want)
+	}
+}
+
+func TestPinUnsupported(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.start("room", "-pin", "Pizza", "Tacos")
+	if reply := tp.run("room", "alice", "!poll end"); reply == "" {
+		t.Error("end of a pinned poll without a pinning broker replied nothing")
+	}
+}
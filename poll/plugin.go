@@ -1,12 +1,17 @@
 package poll
 
 import (
+	"errors"
 	"fmt"
+	"net/url"
+	"sort"
 	"strconv"
 	"strings"
-	"sync"
+	"time"
+	"unicode"
 
 	"github.com/netflix/hal-9001/hal"
+	"golang.org/x/text/unicode/norm"
 )
 
 const usage = `Usage: !poll <command> [arg...]
@@ -15,236 +20,1633 @@ Poll.
 
 Commands:
 
-!poll show
-    Show the poll
-!poll new <title>
-    Create a new poll
+!poll show [-style=fraction] [-lang=L]
+    Show the poll, optionally as each option's share of the votes, in the
+    language of the room or the one given, e.g. -lang=ja
+!poll export [-format=monospace]
+    Show the results as a code block with aligned columns, e.g. to paste them
+    in a wiki
+!poll index
+    List the options with the indices to vote with
+!poll new [flag...] [--] <title>
+    Create a new poll, with the flags below. A title starting with a hyphen
+    follows --, e.g. !poll new -- -1 or +1?
+    -allow=@user,...  only the listed users and the creator may vote
+    -winners=N        report the top N options at the end
+    -quiet            confirm votes without the results
+    -duration=D       close the poll a duration such as 10m after it starts
+    -min=N            require at least N options to start the poll
+    -open             reveal who voted for what at the end
+    -tiebreak=random  break a tie for the most votes at random
+    -seed=N           seed the random decisions of the poll with N
+    -quiz             make the poll a quiz, see !poll answer
+    -raffle           draw the winner at random, weighted by the votes
+    -mintenure=D      only let users who joined at least a duration such as
+                      720h ago vote, where the broker can tell
+    -autoclose        close the poll once every member of the room voted,
+                      where the broker can tell
+    -verbs=V,...      vote for the options in order with !poll <verb>, one
+                      verb per option in any case, e.g. -verbs=approve,reject
+    -secrettitle      show the title to the creator alone until the poll ends
+    -budget=N         let voters allocate N points across the options, see
+                      !poll allocate
+    -reactions        count the number reactions to the poll message as votes,
+                      where the broker tells the message
+    -snapshot=D       record the tally every duration such as 1m, see
+                      !poll timeline
+    -ack="terms"      hold the votes until their voters acknowledge the terms
+                      with !poll ack
+    -quorum=N         announce when N users voted, or a share of the members
+                      of the room such as 60%, and tell at the end whether
+                      the poll reached it
+    -hideuntilvote    show the results to the creator and the users who voted
+                      alone until the poll ends
+    -hiddenmsg="text" show the text in place of the hidden results, e.g.
+                      -hiddenmsg="Results revealed at close"
+    -pin              pin the poll message while the poll runs, where the
+                      broker tells the message and can pin it
+    -antisnipe=D      push the deadline out by a duration such as 30s when a
+                      vote comes in that close to it, up to antisnipe.max times
+    -federation=K     share the poll with the rooms whose federation pref is K
+    -lockvotes=D      let voters change their vote for a duration such as 5m
+                      after the poll starts
+    -force            replace the existing poll
+!poll import <json>
+    Create a poll from a JSON document, e.g. {"title": "Lunch?", "options": ["Pizza", "Tacos"]}
+!poll chain <json>
+    Start a poll from a JSON document, as for import, once the poll ends
+!poll announce <text>
+    Announce an upcoming poll, e.g. "at 2pm about the team lunch"
 !poll remove
     Remove the poll
-!poll option <option>
-    Add an option to the poll
+!poll describe [text]
+    Set the description of the poll, or clear it if no text is given
+!poll option <option> [=alias] [@url]
+    Add an option to the poll, optionally with a short alias to vote with and a
+    link to an image or page
+!poll options <option> | <option>...
+    Add several options to the poll, separated by the room's delimiter
+!poll lockoptions
+    Only let the creator of the poll add options
+!poll unlockoptions
+    Let everybody add options
+!poll unoption <index>
+    Remove an option before the poll starts
+!poll sample <n>
+    List n options of the poll picked at random, e.g. to review write-ins
+!poll draft
+    List the options of the poll before it starts, with the commands to edit
+    them
+!poll move <from> <to>
+    Move an option to another position before the poll starts
+!poll note <index> [text]
+    Attach a note only you can see to an option of your poll, or clear it if
+    no text is given
+!poll threshold <index> <votes>
+    Run the actions set up for the option once it has this many votes, or
+    clear its threshold with 0
+!poll details
+    Show the poll along with your notes on its options
+!poll config
+    List the flags the poll was created with
+!poll answer <index>
+    Set the correct answer of a quiz, revealed at the end
 !poll start
     Start the poll
-!poll end
-    Stop the currently running poll
-!poll vote <index>
-    Vote for the currently running poll
+!poll end [-pin]
+    Stop the currently running poll, pinning the results with -pin where the
+    broker can
+!poll reopen-abstainers
+    Start the poll that ended again, letting only the members of the room who
+    didn't vote in it vote, where the broker can list the members
+!poll runoff
+    Stop the currently running poll and start a new one between its two
+    leading options
+!poll archive
+    Stop the currently running poll and store its results in the storage of
+    the plugin
+!poll archived <code>
+    Show a poll archived in the room, by its code as shown by !poll board
+!poll decide [code]
+    Record the winner of the poll that ended last, or of the ended poll with
+    the code, as a decision, e.g. "Decision: Pizza (carried 7-3) on
+    2024-05-01"
+!poll compare <code>
+    Compare the results of the poll with those of the ended poll with the
+    code, kept since it ended or archived in the room
+!poll time
+    Show the time left until the poll closes
+!poll extend <duration>
+    Push the deadline of the poll out, e.g. by 5m
+!poll vote <index|alias|option>
+    Vote for the currently running poll, also as !poll +1 <index>
+!poll allocate <index>=<points>...
+    Allocate your points across the options of a poll created with -budget,
+    e.g. !poll allocate 1=40 2=60
+!poll ack
+    Acknowledge the terms of a poll created with -ack, casting the vote held
+    until then
+!poll votefor <@user> <index>
+    Vote on behalf of another user (admins only)
+!poll delegate <@from> [@to]
+    Let a user vote on behalf of another user who hasn't voted, or withdraw the
+    delegation if no user is given. Users delegate their own vote, admins that
+    of anybody
+!poll combine <room id>
+    Merge the votes of the poll of another room into the poll, for the
+    creator or a poll admin of both polls
+!poll comment <text>
+    Comment on the poll
+!poll comments
+    Show the comments on the poll
+!poll changes
+    Show the votes cast since you last looked at the poll
+!poll pending
+    List the members who haven't voted in a poll created with -open
+!poll board
+    List the poll and the polls closed recently in the room
+!poll timeline
+    Show when each of the last 10000 votes was cast, and the snapshots of the
+    tally
+!poll metrics
+    Show usage metrics of the polls in all rooms (admins only)
+!poll debug [-force]
+    Dump the internal state of the poll, naming the voters of anonymous polls
+    only with -force (admins only)
+!poll capabilities
+    List the commands, flags and integrations of the plugin, one per line
+!poll enable
+    Allow polls in the room (admins only)
+!poll disable
+    Refuse the commands of everybody but admins in the room (admins only)
+!poll selftest
+    Check that the plugin and its storage work (admins only)
+!poll clear-inactive
+    Remove the poll of the room if it hasn't started and forget the ended polls
+    of the room, archiving them first where the clear.archive pref is set
+    (admins only), once confirmed
+!poll confirm <token>
+    Confirm a command that cannot be undone, such as clear-inactive, within 2
+    minutes
 `
 
-var (
-	polls map[string]*pollEntry
-	mutex sync.Mutex
-)
+// getPref looks up a room-level preference of a poll plugin.
+var getPref = func(plugin, roomId, key, def string) string {
+	return hal.GetPref("", "", roomId, plugin, key, def).Value
+}
 
-func init() {
-	polls = make(map[string]*pollEntry)
+// setPref sets a room-level preference of a poll plugin.
+var setPref = func(plugin, roomId, key, value string) error {
+	pref := hal.Pref{Room: roomId, Plugin: plugin, Key: key, Value: value}
+	return pref.Set()
+}
+
+// resultLimit returns the number of options shown by show and end, as set
+// by the room's result.limit pref. Zero means all options are shown.
+func (pl *Poller) resultLimit(roomId string) int {
+	limit, err := strconv.Atoi(pl.pref(roomId, "result.limit", "0"))
+	if err != nil || limit < 0 {
+		return 0
+	}
+	return limit
 }
 
 type pollOption struct {
-	Text  string
-	Votes int
+	Text     string
+	Votes    int
+	Alias    string
+	URL      string
+	Original string // the text as added, if normalized
+
+	// CreatorNote is a note of the creator, only shown to the creator.
+	CreatorNote string
+
+	// Threshold is the number of votes calling the threshold hooks, see
+	// RegisterThresholdHook, and ThresholdReached is set once it did.
+	Threshold        int
+	ThresholdReached bool
+}
+
+// typed returns the text of the option as added, before the options.case
+// pref normalized it.
+func (o pollOption) typed() string {
+	if o.Original != "" {
+		return o.Original
+	}
+	return o.Text
+}
+
+// label returns the text of the option followed by its alias and link, if
+// any.
+func (o pollOption) label() string {
+	label := o.Text
+	if o.Alias != "" {
+		label = fmt.Sprintf("%s [%s]", label, o.Alias)
+	}
+	if o.URL != "" {
+		label = fmt.Sprintf("%s <%s>", label, o.URL)
+	}
+	return label
+}
+
+// maxTimeline is the number of votes retained in the timeline of a poll.
+// Older votes are dropped first.
+const maxTimeline = 10000
+
+// voteRecord is the record of a single vote, kept for the timeline.
+type voteRecord struct {
+	Option int
+	Time   time.Time
+}
+
+// ballot records the choice of a voter. Ballots are only revealed for open
+// polls.
+type ballot struct {
+	UserId   string
+	UserName string
+	Option   int
 }
 
 type pollEntry struct {
-	Title    string
-	Options  []pollOption
-	HasVoted []string
-	IsActive bool
+	Title         string
+	Description   string
+	Options       []pollOption
+	HasVoted      []string
+	IsActive      bool
+	Creator       string
+	Allow         []string
+	Timeline      []voteRecord
+	Winners       int
+	Quiet         bool
+	Audit         []auditRecord
+	Comments      []comment
+	Duration      time.Duration
+	Deadline      time.Time
+	MinOptions    int
+	Open          bool
+	Ballots       []ballot
+	Next          []pollDocument
+	OptionsLocked bool
+	Seed          int64
+	TieBreak      string
+	Quiz          bool
+	Raffle        bool
+	MinTenure     time.Duration
+	AutoClose     bool
+	Verbs         []string
+	SecretTitle   bool
+	Budget        int
+	Reactions     bool
+	SnapshotEvery time.Duration
+	Snapshots     []snapshot
+	Ack           string   // the terms voters acknowledge before their votes count
+	Acked         []string // the users who acknowledged the terms
+	Code          string   // the short code of the poll, unique in the room
+	MessageId     string   // the poll message kept up to date, see replyNew
+	Quorum        int      // the number of voters announced once reached
+	QuorumPercent int      // the quorum as a share of the members of the room
+	Pin           bool     // pin the poll message while the poll runs
+	Abstainers    []string // the only voters of a poll reopened for them
+	Federation    string   // the federation of rooms sharing the poll
+	HideUntilVote bool     // hide the results from users yet to vote
+	HiddenMessage string   // shown in place of the hidden results
+	Answer        int
+	LockVotes     time.Duration
+	StartedAt     time.Time
+	AntiSnipe     time.Duration // how close to the deadline votes push it out
+	Extensions    int           // the times a late vote pushed the deadline out
+
+	// QuorumAnnounced is set once the quorum was announced, so that it is
+	// announced a single time.
+	QuorumAnnounced bool
+
+	origin hal.Evt // the event that created the poll
+	nudge  Timer
+	nudged bool
+	closer Timer
+	// snapshotter takes the next snapshot of a poll created with -snapshot
+	snapshotter Timer
+	// countdown updates the time left shown by the poll message
+	countdown Timer
+
+	lastVote map[string]time.Time      // when each user last voted, for the cooldown
+	rooms    map[string]hal.Evt        // the last event of each federated room
+	seen     map[string]map[string]int // the tallies each user last looked at
+	held     map[string]int            // the votes held until their users ack
+	votes    tokenBucket               // the rate limit of the votes
+}
+
+// CanVote reports whether the user may vote in the poll. The creator can
+// always vote, everybody else only if there is no allowlist or they are on it.
+func (p pollEntry) CanVote(userId, userName string) bool {
+	if len(p.Allow) == 0 || userId == p.Creator {
+		return true
+	}
+	for _, user := range p.Allow {
+		if user == userId || user == userName {
+			return true
+		}
+	}
+	return false
+}
+
+// Status describes the state of the poll in a few words.
+func (p pollEntry) Status() string {
+	if p.IsActive {
+		return fmt.Sprintf("active, %d votes", len(p.HasVoted))
+	}
+	return fmt.Sprintf("inactive, %d options", len(p.Options))
+}
+
+// Reveal lists the choice of each voter, e.g. "alice→Pizza, bob→Tacos".
+func (p pollEntry) Reveal() string {
+	votes := make([]string, 0, len(p.Ballots))
+	for _, b := range p.Ballots {
+		voter := b.UserName
+		if voter == "" {
+			voter = b.UserId
+		}
+		votes = append(votes, fmt.Sprintf("%s→%s", voter, p.Options[b.Option].Text))
+	}
+	return strings.Join(votes, ", ")
+}
+
+// minOptions returns the number of options the poll needs to start.
+func (p pollEntry) minOptions() int {
+	if p.MinOptions > 2 {
+		return p.MinOptions
+	}
+	return 2
 }
 
 func (p pollEntry) Result() string {
+	return p.result(0)
+}
+
+// Details is like Result, but includes the description under the title.
+func (p pollEntry) Details() string {
+	return p.details(0)
+}
+
+// result renders the poll, showing only the top limit options by votes
+// when limit is positive.
+func (p pollEntry) result(limit int) string {
+	return fmt.Sprintf("%s\n%s", p.Title, p.optionLines(langDefault, limit))
+}
+
+func (p pollEntry) details(limit int) string {
+	return p.withHeader(p.optionLines(langDefault, limit))
+}
+
+// distinctOptions counts the options that differ once case and whitespace are
+// ignored, and describes each option that collapsed into an earlier one.
+func (p pollEntry) distinctOptions() (int, []string) {
+	seen := make(map[string]string)
+	var duplicates []string
+	for _, o := range p.Options {
+		key := optionKey(o.Text)
+		if first, ok := seen[key]; ok {
+			duplicates = append(duplicates, fmt.Sprintf("'%s' is the same as '%s'", o.Text, first))
+			continue
+		}
+		seen[key] = o.Text
+	}
+	return len(seen), duplicates
+}
+
+// titleCase upper-cases the first letter of each word of text, e.g.
+// "pizza place" becomes "Pizza Place". The other letters are kept as they
+// are, so that acronyms such as "NYC" survive.
+func titleCase(text string) string {
+	var b strings.Builder
+	start := true
+	for _, r := range text {
+		if start {
+			b.WriteRune(unicode.ToUpper(r))
+		} else {
+			b.WriteRune(r)
+		}
+		start = unicode.IsSpace(r)
+	}
+	return b.String()
+}
+
+// optionKey returns the form of an option text used to compare options.
+// The text is NFC normalized so that visually identical options compare
+// equal regardless of their Unicode composition.
+func optionKey(text string) string {
+	return strings.ToLower(strings.Join(strings.Fields(norm.NFC.String(text)), " "))
+}
+
+// byVotes returns the indices of the options ordered by votes, most voted
+// first. Options with the same number of votes keep their order.
+func (p pollEntry) byVotes() []int {
+	indices := make([]int, len(p.Options))
+	for k := range p.Options {
+		indices[k] = k
+	}
+	sort.SliceStable(indices, func(i, j int) bool {
+		return p.Options[indices[i]].Votes > p.Options[indices[j]].Votes
+	})
+	return indices
+}
+
+// Ranking returns the ranking of the top n options. Options tied with the
+// nth one are all included, so more than n options may be returned.
+func (p pollEntry) Ranking(n int) string {
+	ranked := p.byVotes()
+	if n > len(ranked) {
+		n = len(ranked)
+	}
+	if n < len(ranked) {
+		last := p.Options[ranked[n-1]].Votes
+		for n < len(ranked) && p.Options[ranked[n]].Votes == last {
+			n++
+		}
+	}
+
+	lines := make([]string, 0, n)
+	rank := 0
+	for i, k := range ranked[:n] {
+		if i == 0 || p.Options[k].Votes != p.Options[ranked[i-1]].Votes {
+			rank = i + 1
+		}
+		lines = append(lines, fmt.Sprintf(" #%d %s (%s votes)", rank, p.Options[k].Text, formatCount(p.Options[k].Votes)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// optionLines renders the options with their votes in the language, showing
+// only the top limit options by votes when limit is positive.
+func (p pollEntry) optionLines(lang string, limit int) string {
+	indices := make([]int, len(p.Options))
+	for k := range p.Options {
+		indices[k] = k
+	}
+	more := 0
+	if limit > 0 && limit < len(indices) {
+		indices = p.byVotes()
+		more = len(indices) - limit
+		indices = indices[:limit]
+	}
+
+	format := tr(lang, " %d. %s (%s votes)")
+	if p.Budget > 0 {
+		format = tr(lang, " %d. %s (%s points)")
+	}
 	options := ""
-	for k, o := range p.Options {
-		options = fmt.Sprintf("%s %d. %s (%d votes)\n", options, k+1, o.Text, o.Votes)
+	for _, k := range indices {
+		o := p.Options[k]
+		options = fmt.Sprintf("%s"+format+"\n", options, k+1, o.label(), formatCount(o.Votes))
 	}
-	return fmt.Sprintf("%s\n%s", p.Title, strings.Trim(options, "\n"))
+	if more > 0 {
+		options = fmt.Sprintf("%s"+tr(lang, " ...and %d more options")+"\n", options, more)
+	}
+	return strings.Trim(options, "\n")
 }
 
+// Register registers the default instance of the plugin as "poll". It is
+// safe to call more than once, the plugin is only registered once.
 func Register() {
-	p := hal.Plugin{
-		Name:  "poll",
-		Func:  poll,
-		Regex: "^[[:space:]]*!poll",
+	defaultPoller.Register("poll", "^[[:space:]]*!poll")
+}
+
+// Unregister unregisters the default instance of the plugin, e.g. to tear
+// it down between tests.
+func Unregister() {
+	defaultPoller.Unregister()
+}
+
+// Register registers the instance as the plugin name, handling the messages
+// matching regex. The name is also the plugin the prefs are looked up for,
+// so Register must be called before the instance is used. Registering an
+// instance already registered does nothing.
+func (pl *Poller) Register(name, regex string) {
+	pl.registerMutex.Lock()
+	defer pl.registerMutex.Unlock()
+
+	if pl.plugin != nil {
+		return
+	}
+	pl.name = name
+	p := &hal.Plugin{
+		Name:  name,
+		Func:  pl.poll,
+		Regex: regex,
+	}
+	if err := p.Register(); err != nil {
+		pl.log().Warn("registering the plugin failed", "err", err)
+		return
+	}
+	pl.plugin = p
+	if _, ok := currentStorage().(*memStorage); ok {
+		pl.log().Warn("no storage set, the archived polls are kept in memory and lost on restart, see SetStorage")
 	}
-	p.Register()
 }
 
-func poll(evt hal.Evt) {
+// Unregister unregisters the instance, which can then be registered again.
+func (pl *Poller) Unregister() {
+	pl.registerMutex.Lock()
+	defer pl.registerMutex.Unlock()
+
+	if pl.plugin == nil {
+		return
+	}
+	if err := pl.plugin.Unregister(); err != nil {
+		pl.log().Warn("unregistering the plugin failed", "err", err)
+		return
+	}
+	pl.plugin = nil
+}
+
+func (pl *Poller) poll(evt hal.Evt) {
+	// brokers occasionally deliver the same message twice
+	if evt.ID != "" && pl.recentEvents.Seen(evt.RoomId+"/"+evt.ID) {
+		return
+	}
+
 	argv := evt.BodyAsArgv()
 	if len(argv) < 2 {
-		evt.Reply(usage)
+		pl.reply(evt, usage)
 		return
 	}
 
-	switch argv[1] {
-	case "show":
-		evt.Reply(pollShow(evt.RoomId))
+	if pl.isVoteAlias(evt.RoomId, argv[1]) {
+		argv[1] = "vote"
+	}
+	if msg := pl.refusal(evt.RoomId, evt.UserId, argv[1]); msg != "" {
+		pl.log().Warn("command refused", "room", evt.RoomId, "user", evt.UserId, "command", argv[1], "reason", msg)
+		pl.reply(evt, msg)
 		return
-	case "new":
-		if len(argv) < 3 {
-			evt.Reply("Usage: !poll new <title>")
-			return
-		}
-		evt.Reply(pollNew(evt.RoomId, strings.Join(argv[2:], " ")))
+	}
+	pl.logCommand(evt, argv[1])
+
+	// the rooms of a federation share the poll of one of them
+	roomId, msg := pl.homeRoom(evt, argv[1])
+	if msg != "" {
+		pl.reply(evt, msg)
 		return
-	case "remove":
-		evt.Reply(pollRemove(evt.RoomId))
+	}
+	pl.fetchMembers(roomId)
+
+	if run, ok := commands[argv[1]]; ok {
+		run(pl, evt, roomId, argv)
 		return
-	case "option":
-		if len(argv) < 3 {
-			evt.Reply("Usage: !poll option <option>")
+	}
+	if index := pl.verbIndex(roomId, argv[1]); index > 0 {
+		if msg := pl.refusal(roomId, evt.UserId, "vote"); msg != "" {
+			pl.log().Warn("command refused", "room", roomId, "user", evt.UserId, "command", "vote", "reason", msg)
+			pl.reply(evt, msg)
 			return
 		}
-		evt.Reply(pollAddOption(evt.RoomId, strings.Join(argv[2:], " ")))
-		return
-	case "start":
-		evt.Reply(pollStart(evt.RoomId))
+		pl.vote(evt, roomId, index)
 		return
-	case "end":
-		evt.Reply(pollEnd(evt.RoomId))
-		return
-	case "vote":
-		if len(argv) < 3 {
-			evt.Reply("Usage: !poll vote <index>")
-			return
+	}
+	pl.reply(evt, "Wrong command.")
+	pl.reply(evt, usage)
+}
+
+// commandFunc runs a command of the plugin, with the room keeping the poll
+// the command is for and the arguments of the event.
+type commandFunc func(pl *Poller, evt hal.Evt, roomId string, argv []string)
+
+// commands are the commands of the plugin by name, dispatched by poll. They
+// are set by init, as some of them look the commands up.
+var commands map[string]commandFunc
+
+func init() {
+	commands = map[string]commandFunc{
+		"show": func(pl *Poller, evt hal.Evt, roomId string, argv []string) {
+			opts, _, err := parseShowOptions(argv[2:])
+			if err != nil {
+				pl.reply(evt, err.Error())
+				return
+			}
+			pl.replyShow(evt, pl.pollShow(roomId, evt.UserId, opts), pl.lang(roomId, opts.Lang))
+		},
+		"export": func(pl *Poller, evt hal.Evt, roomId string, argv []string) {
+			opts, _, err := parseExportOptions(argv[2:])
+			if err != nil {
+				pl.reply(evt, err.Error())
+				return
+			}
+			pl.replyResults(evt, pl.pollExport(roomId, evt.UserId, opts))
+		},
+		"index": func(pl *Poller, evt hal.Evt, roomId string, argv []string) {
+			pl.reply(evt, pl.pollIndex(roomId))
+		},
+		"new": func(pl *Poller, evt hal.Evt, roomId string, argv []string) {
+			opts, title, err := parseNewOptions(argv[2:])
+			if err != nil {
+				pl.reply(evt, err.Error())
+				return
+			}
+			if len(title) == 0 {
+				pl.reply(evt, "Usage: !poll new [flag...] [--] <title>")
+				return
+			}
+			for k, user := range opts.Allow {
+				opts.Allow[k] = resolveUser(evt.Broker, user)
+			}
+			opts.Origin = evt
+			pl.replyNew(evt, pl.pollNew(roomId, evt.UserId, strings.Join(title, " "), opts))
+		},
+		"import": func(pl *Poller, evt hal.Evt, roomId string, argv []string) {
+			data := rawArgs(evt.Body, argv[1])
+			if data == "" {
+				pl.reply(evt, "Usage: !poll import <json>")
+				return
+			}
+			pl.reply(evt, pl.pollImport(roomId, evt.UserId, data))
+		},
+		"chain": func(pl *Poller, evt hal.Evt, roomId string, argv []string) {
+			data := rawArgs(evt.Body, argv[1])
+			if data == "" {
+				pl.reply(evt, "Usage: !poll chain <json>")
+				return
+			}
+			pl.reply(evt, pl.pollChain(roomId, evt.UserId, data))
+		},
+		"announce": func(pl *Poller, evt hal.Evt, roomId string, argv []string) {
+			text := rawArgs(evt.Body, argv[1])
+			if text == "" {
+				pl.reply(evt, "Usage: !poll announce <text>")
+				return
+			}
+			pl.reply(evt, pollAnnounce(text))
+		},
+		"remove": func(pl *Poller, evt hal.Evt, roomId string, argv []string) {
+			pl.reply(evt, pl.pollRemove(roomId))
+		},
+		"describe": func(pl *Poller, evt hal.Evt, roomId string, argv []string) {
+			pl.reply(evt, pl.pollDescribe(roomId, strings.Join(argv[2:], " ")))
+		},
+		"option": func(pl *Poller, evt hal.Evt, roomId string, argv []string) {
+			if len(argv) < 3 {
+				pl.reply(evt, "Usage: !poll option <option> [=alias] [@url]")
+				return
+			}
+			pl.reply(evt, pl.pollAddOption(roomId, evt.UserId, strings.Join(argv[2:], " ")))
+		},
+		"options": func(pl *Poller, evt hal.Evt, roomId string, argv []string) {
+			if len(argv) < 3 {
+				pl.reply(evt, "Usage: !poll options <option> | <option>...")
+				return
+			}
+			pl.reply(evt, pl.pollAddOptions(roomId, evt.UserId, strings.Join(argv[2:], " ")))
+		},
+		"lockoptions": func(pl *Poller, evt hal.Evt, roomId string, argv []string) {
+			pl.reply(evt, pl.pollLockOptions(roomId, evt.UserId, true))
+		},
+		"unlockoptions": func(pl *Poller, evt hal.Evt, roomId string, argv []string) {
+			pl.reply(evt, pl.pollLockOptions(roomId, evt.UserId, false))
+		},
+		"unoption": func(pl *Poller, evt hal.Evt, roomId string, argv []string) {
+			if len(argv) < 3 {
+				pl.reply(evt, "Usage: !poll unoption <index>")
+				return
+			}
+			index, err := strconv.Atoi(argv[2])
+			if err != nil {
+				pl.reply(evt, "Please use the numerical index of the option.")
+				return
+			}
+			pl.reply(evt, pl.pollUnoption(roomId, evt.UserId, index))
+		},
+		"sample": func(pl *Poller, evt hal.Evt, roomId string, argv []string) {
+			if len(argv) < 3 {
+				pl.reply(evt, "Usage: !poll sample <n>")
+				return
+			}
+			n, err := strconv.Atoi(argv[2])
+			if err != nil {
+				pl.reply(evt, "Please give the number of options to sample.")
+				return
+			}
+			pl.reply(evt, pl.pollSample(roomId, n))
+		},
+		"draft": func(pl *Poller, evt hal.Evt, roomId string, argv []string) {
+			pl.reply(evt, pl.pollDraft(roomId))
+		},
+		"move": func(pl *Poller, evt hal.Evt, roomId string, argv []string) {
+			if len(argv) < 4 {
+				pl.reply(evt, "Usage: !poll move <from> <to>")
+				return
+			}
+			from, err1 := strconv.Atoi(argv[2])
+			to, err2 := strconv.Atoi(argv[3])
+			if err1 != nil || err2 != nil {
+				pl.reply(evt, "Please use the numerical indices of the options.")
+				return
+			}
+			pl.reply(evt, pl.pollMove(roomId, from, to))
+		},
+		"note": func(pl *Poller, evt hal.Evt, roomId string, argv []string) {
+			if len(argv) < 3 {
+				pl.reply(evt, "Usage: !poll note <index> [text]")
+				return
+			}
+			index, err := strconv.Atoi(argv[2])
+			if err != nil {
+				pl.reply(evt, "Please use the numerical index of the option.")
+				return
+			}
+			pl.replyPrivately(evt, pl.pollNote(roomId, evt.UserId, index, strings.Join(argv[3:], " ")))
+		},
+		"threshold": func(pl *Poller, evt hal.Evt, roomId string, argv []string) {
+			if len(argv) < 4 {
+				pl.reply(evt, "Usage: !poll threshold <index> <votes>")
+				return
+			}
+			index, err := strconv.Atoi(argv[2])
+			if err != nil {
+				pl.reply(evt, "Please use the numerical index of the option.")
+				return
+			}
+			votes, err := strconv.Atoi(argv[3])
+			if err != nil {
+				pl.reply(evt, "Please give the threshold as a number of votes.")
+				return
+			}
+			pl.reply(evt, pl.pollThreshold(roomId, evt.UserId, index, votes))
+		},
+		"details": func(pl *Poller, evt hal.Evt, roomId string, argv []string) {
+			pl.replyPrivately(evt, pl.pollDetails(roomId, evt.UserId))
+		},
+		"config": func(pl *Poller, evt hal.Evt, roomId string, argv []string) {
+			pl.reply(evt, pl.pollConfig(roomId, evt.UserId))
+		},
+		"answer": func(pl *Poller, evt hal.Evt, roomId string, argv []string) {
+			if len(argv) < 3 {
+				pl.reply(evt, "Usage: !poll answer <index>")
+				return
+			}
+			index, err := strconv.Atoi(argv[2])
+			if err != nil {
+				pl.reply(evt, "Please use the numerical index of the option.")
+				return
+			}
+			pl.replyPrivately(evt, pl.pollAnswer(roomId, evt.UserId, index))
+		},
+		"start": func(pl *Poller, evt hal.Evt, roomId string, argv []string) {
+			pl.reply(evt, pl.pollStart(roomId))
+		},
+		"end": func(pl *Poller, evt hal.Evt, roomId string, argv []string) {
+			results, ended := pl.pollEnd(roomId, evt.RoomId)
+			if ended && len(argv) > 2 && argv[2] == "-pin" {
+				pl.replyPinned(evt, results)
+				return
+			}
+			pl.replyResults(evt, results)
+		},
+		"reopen-abstainers": func(pl *Poller, evt hal.Evt, roomId string, argv []string) {
+			pl.reply(evt, pl.pollReopenAbstainers(roomId, evt.UserId))
+		},
+		"runoff": func(pl *Poller, evt hal.Evt, roomId string, argv []string) {
+			pl.reply(evt, pl.pollRunoff(roomId, evt.UserId, evt.RoomId))
+		},
+		"archive": func(pl *Poller, evt hal.Evt, roomId string, argv []string) {
+			pl.replyResults(evt, pl.pollArchive(roomId, evt.RoomId))
+		},
+		"archived": func(pl *Poller, evt hal.Evt, roomId string, argv []string) {
+			if len(argv) < 3 {
+				pl.reply(evt, "Usage: !poll archived <code>")
+				return
+			}
+			pl.reply(evt, pl.pollArchived(roomId, argv[2]))
+		},
+		"decide": func(pl *Poller, evt hal.Evt, roomId string, argv []string) {
+			code := ""
+			if len(argv) > 2 {
+				code = argv[2]
+			}
+			pl.reply(evt, pl.pollDecide(roomId, code))
+		},
+		"compare": func(pl *Poller, evt hal.Evt, roomId string, argv []string) {
+			if len(argv) < 3 {
+				pl.reply(evt, "Usage: !poll compare <code>")
+				return
+			}
+			pl.reply(evt, pl.pollCompare(roomId, evt.UserId, argv[2]))
+		},
+		"time": func(pl *Poller, evt hal.Evt, roomId string, argv []string) {
+			pl.reply(evt, pl.pollTime(roomId))
+		},
+		"extend": func(pl *Poller, evt hal.Evt, roomId string, argv []string) {
+			if len(argv) < 3 {
+				pl.reply(evt, "Usage: !poll extend <duration>")
+				return
+			}
+			d, err := time.ParseDuration(argv[2])
+			if err != nil {
+				pl.reply(evt, "Please give a duration such as 5m.")
+				return
+			}
+			pl.reply(evt, pl.pollExtend(roomId, evt.UserId, d))
+		},
+		"vote": func(pl *Poller, evt hal.Evt, roomId string, argv []string) {
+			// BodyAsArgv may keep empty or whitespace-only arguments
+			if len(argv) < 3 || strings.TrimSpace(strings.Join(argv[2:], "")) == "" {
+				pl.reply(evt, "Usage: !poll vote <index|alias|option>")
+				return
+			}
+			index, err := strconv.Atoi(strings.TrimSpace(argv[2]))
+			if err != nil {
+				if index = pl.findOption(roomId, strings.Join(argv[2:], " ")); index == 0 {
+					pl.reply(evt, "Please vote using the numerical index, the alias or the text of the option.")
+					return
+				}
+			}
+			pl.vote(evt, roomId, index)
+		},
+		"allocate": func(pl *Poller, evt hal.Evt, roomId string, argv []string) {
+			if len(argv) < 3 {
+				pl.reply(evt, "Usage: !poll allocate <index>=<points>...")
+				return
+			}
+			pl.replyVote(evt, pl.pollAllocate(roomId, evt.UserId, evt.User, argv[2:]))
+		},
+		"ack": func(pl *Poller, evt hal.Evt, roomId string, argv []string) {
+			pl.replyVote(evt, pl.pollAck(roomId, evt.UserId, evt.User))
+		},
+		"votefor": func(pl *Poller, evt hal.Evt, roomId string, argv []string) {
+			if len(argv) < 4 {
+				pl.reply(evt, "Usage: !poll votefor <@user> <index>")
+				return
+			}
+			index, err := strconv.Atoi(argv[3])
+			if err != nil {
+				pl.reply(evt, "Please vote using the numerical index of the option.")
+				return
+			}
+			pl.reply(evt, pl.pollVoteFor(roomId, evt.UserId, resolveUser(evt.Broker, argv[2]), index))
+		},
+		"delegate": func(pl *Poller, evt hal.Evt, roomId string, argv []string) {
+			if len(argv) < 3 {
+				pl.reply(evt, "Usage: !poll delegate <@from> [@to]")
+				return
+			}
+			to := ""
+			if len(argv) > 3 {
+				to = resolveUser(evt.Broker, argv[3])
+			}
+			pl.reply(evt, pl.pollDelegate(roomId, evt.UserId, resolveUser(evt.Broker, argv[2]), to))
+		},
+		"combine": func(pl *Poller, evt hal.Evt, roomId string, argv []string) {
+			if len(argv) < 3 {
+				pl.reply(evt, "Usage: !poll combine <room id>")
+				return
+			}
+			pl.reply(evt, pl.pollCombine(roomId, evt.UserId, argv[2]))
+		},
+		"comment": func(pl *Poller, evt hal.Evt, roomId string, argv []string) {
+			if len(argv) < 3 {
+				pl.reply(evt, "Usage: !poll comment <text>")
+				return
+			}
+			pl.reply(evt, pl.pollAddComment(roomId, evt.User, strings.Join(argv[2:], " ")))
+		},
+		"comments": func(pl *Poller, evt hal.Evt, roomId string, argv []string) {
+			pl.reply(evt, pl.pollComments(roomId))
+		},
+		"changes": func(pl *Poller, evt hal.Evt, roomId string, argv []string) {
+			pl.reply(evt, pl.pollChanges(roomId, evt.UserId))
+		},
+		"pending": func(pl *Poller, evt hal.Evt, roomId string, argv []string) {
+			pl.reply(evt, pl.pollPending(roomId, evt.UserId))
+		},
+		"board": func(pl *Poller, evt hal.Evt, roomId string, argv []string) {
+			pl.reply(evt, pl.pollBoard(roomId))
+		},
+		"timeline": func(pl *Poller, evt hal.Evt, roomId string, argv []string) {
+			pl.reply(evt, pl.pollTimeline(roomId, evt.UserId))
+		},
+		"metrics": func(pl *Poller, evt hal.Evt, roomId string, argv []string) {
+			pl.reply(evt, pl.pollMetrics(roomId, evt.UserId))
+		},
+		"debug": func(pl *Poller, evt hal.Evt, roomId string, argv []string) {
+			force := len(argv) > 2 && argv[2] == "-force"
+			pl.replyPrivately(evt, pl.pollDebug(roomId, evt.UserId, force))
+		},
+		"clear-inactive": func(pl *Poller, evt hal.Evt, roomId string, argv []string) {
+			pl.reply(evt, pl.pollClearInactive(roomId, evt.UserId))
+		},
+		"confirm": func(pl *Poller, evt hal.Evt, roomId string, argv []string) {
+			if len(argv) < 3 {
+				pl.reply(evt, "Usage: !poll confirm <token>")
+				return
+			}
+			pl.reply(evt, pl.pollConfirm(roomId, evt.UserId, argv[2]))
+		},
+		"capabilities": func(pl *Poller, evt hal.Evt, roomId string, argv []string) {
+			pl.reply(evt, strings.Join(BrokerCapabilities(evt.Broker), "\n"))
+		},
+		"enable": func(pl *Poller, evt hal.Evt, roomId string, argv []string) {
+			pl.reply(evt, pl.pollEnable(roomId, evt.UserId, true))
+		},
+		"disable": func(pl *Poller, evt hal.Evt, roomId string, argv []string) {
+			pl.reply(evt, pl.pollEnable(roomId, evt.UserId, false))
+		},
+		"selftest": func(pl *Poller, evt hal.Evt, roomId string, argv []string) {
+			pl.reply(evt, pl.pollSelftest(roomId, evt.UserId))
+		},
+	}
+}
+
+// isVoteAlias reports whether the command is one of the room's
+// comma-separated vote.aliases pref, "+1" by default, voting like vote.
+func (pl *Poller) isVoteAlias(roomId, command string) bool {
+	for _, alias := range strings.Split(pl.pref(roomId, "vote.aliases", "+1"), ",") {
+		if alias = strings.TrimSpace(alias); alias != "" && strings.EqualFold(alias, command) {
+			return true
 		}
-		index, err := strconv.Atoi(argv[2])
-		if err != nil {
-			evt.Reply("Please vote using the numerical index of the option.")
+	}
+	return false
+}
+
+// splitArgs splits text on the room's delimiter pref, "|" by default, and
+// drops the empty parts.
+func (pl *Poller) splitArgs(roomId, text string) []string {
+	delimiter := pl.pref(roomId, "delimiter", "|")
+	if delimiter == "" {
+		delimiter = "|"
+	}
+	var parts []string
+	for _, part := range strings.Split(text, delimiter) {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
 		}
-		evt.Reply(pollVote(evt.RoomId, evt.UserId, index))
-		return
-	default:
-		evt.Reply("Wrong command.")
-		evt.Reply(usage)
-		return
 	}
+	return parts
+}
+
+// rawArgs returns the body text following the command, untouched by the
+// argument splitting of BodyAsArgv.
+func rawArgs(body, command string) string {
+	i := strings.Index(body, command)
+	if i < 0 {
+		return ""
+	}
+	return strings.TrimSpace(body[i+len(command):])
 }
 
-func pollShow(roomId string) string {
-	mutex.Lock()
-	defer mutex.Unlock()
+func (pl *Poller) pollShow(roomId, userId string, opts showOptions) string {
+	pl.mutex.RLock()
+	defer pl.mutex.RUnlock()
 
-	poll, ok := polls[roomId]
+	lang := pl.lang(roomId, opts.Lang)
+	poll, ok := pl.polls[roomId]
 	if !ok {
-		return "There is no poll."
+		return tr(lang, "There is no poll.")
+	}
+	if poll.hidesResults(userId) {
+		if poll.HiddenMessage == "" {
+			return tr(lang, hiddenResults)
+		}
+		return poll.HiddenMessage
 	}
+	pl.seenMutex.Lock()
+	poll.markSeen(userId)
+	pl.seenMutex.Unlock()
+	view := poll.viewedBy(userId)
 
 	status := ""
 	if !poll.IsActive {
-		status = " (Inactive)"
+		status = tr(lang, " (Inactive)")
 	}
 
-	return fmt.Sprintf("Poll%s:\n%s", status, poll.Result())
+	show := ""
+	if opts.Style == styleFraction {
+		show = fmt.Sprintf(tr(lang, "Poll%s:\n%s"), status, view.withHeader(view.fractionLines(lang)))
+	} else {
+		show = fmt.Sprintf(tr(lang, "Poll%s:\n%s"), status, view.withHeader(view.optionLines(lang, pl.resultLimit(roomId))))
+	}
+	if missing := poll.minOptions() - len(poll.Options); !poll.IsActive && missing > 0 {
+		show = fmt.Sprintf(tr(lang, "%s\nNeeds %d more options."), show, missing)
+	}
+	if line := pl.turnoutLine(roomId, poll, lang); poll.IsActive && line != "" {
+		show = fmt.Sprintf("%s\n%s", show, line)
+	}
+	return show
 }
 
-func pollNew(roomId, title string) string {
-	mutex.Lock()
-	defer mutex.Unlock()
+// pollIndex lists the options in their canonical order, whatever order
+// show displays them in, so that voters can rely on the indices.
+func (pl *Poller) pollIndex(roomId string) string {
+	pl.mutex.RLock()
+	defer pl.mutex.RUnlock()
 
-	if poll, ok := polls[roomId]; ok {
-		return fmt.Sprintf("The poll '%s' already exists.", poll.Title)
+	poll, ok := pl.polls[roomId]
+	if !ok {
+		return "There is no poll."
+	}
+	if len(poll.Options) == 0 {
+		return "There are no options. Use !poll option <option> to add options."
 	}
 
-	polls[roomId] = &pollEntry{Title: title}
+	lines := make([]string, 0, len(poll.Options))
+	for k, o := range poll.Options {
+		lines = append(lines, fmt.Sprintf(" %d. %s", k+1, o.label()))
+	}
+	return fmt.Sprintf("Options of %s, vote with !poll vote <index>:\n%s", poll.viewedBy("").Title, strings.Join(lines, "\n"))
+}
+
+func (pl *Poller) pollNew(roomId, userId, title string, opts newOptions) string {
+	pl.mutex.Lock()
+	defer pl.unlock()
+
+	replaced := ""
+	if poll, ok := pl.polls[roomId]; ok {
+		if !opts.Force {
+			return fmt.Sprintf("The poll '%s' (%s) already exists.\nUse !poll remove to remove it, or !poll new -force <title> to replace it.",
+				poll.viewedBy("").Title, poll.Status())
+		}
+		poll.stopTimers()
+		if poll.IsActive {
+			pl.pinMessage(roomId, poll, false)
+		}
+		replaced = fmt.Sprintf("Poll '%s' replaced.\n", poll.viewedBy("").Title)
+	}
+	if opts.Federation != "" {
+		if err := pl.federate(roomId, opts.Federation); err != nil {
+			return err.Error()
+		}
+	}
 
-	return fmt.Sprintf("Poll '%s' created.\nUse !poll option <option> to add options.", title)
+	poll := &pollEntry{
+		Title:         title,
+		Creator:       userId,
+		Allow:         opts.Allow,
+		Winners:       opts.Winners,
+		Quiet:         opts.Quiet,
+		Duration:      opts.Duration,
+		MinOptions:    opts.MinOptions,
+		Open:          opts.Open,
+		OptionsLocked: pl.pref(roomId, "options.locked", "false") == "true",
+		Seed:          now().UnixNano(),
+		TieBreak:      opts.TieBreak,
+		Quiz:          opts.Quiz,
+		Raffle:        opts.Raffle,
+		MinTenure:     opts.MinTenure,
+		AutoClose:     opts.AutoClose,
+		Verbs:         opts.Verbs,
+		SecretTitle:   opts.SecretTitle,
+		Budget:        opts.Budget,
+		Reactions:     opts.Reactions,
+		SnapshotEvery: opts.SnapshotEvery,
+		Ack:           opts.Ack,
+		Quorum:        opts.Quorum,
+		QuorumPercent: opts.QuorumPercent,
+		Pin:           opts.Pin,
+		AntiSnipe:     opts.AntiSnipe,
+		Federation:    opts.Federation,
+		HideUntilVote: opts.HideUntilVote,
+		HiddenMessage: opts.HiddenMessage,
+		LockVotes:     opts.LockVotes,
+		origin:        opts.Origin,
+	}
+	if opts.Seed != nil {
+		poll.Seed = *opts.Seed
+	}
+	poll.audit(userId, "created the poll with seed %d", poll.Seed)
+	pl.addPoll(roomId, poll)
+	pl.armNudge(roomId, poll)
+
+	return fmt.Sprintf("%sPoll '%s' created.\nUse !poll option <option> to add options.", replaced, title)
+}
+
+// pollAnnounce renders the teaser of an upcoming poll. It leaves the polls
+// alone, so announcing doesn't stand in the way of creating one.
+func pollAnnounce(text string) string {
+	return fmt.Sprintf("Upcoming poll: %s", text)
 }
 
-func pollRemove(roomId string) string {
-	mutex.Lock()
-	defer mutex.Unlock()
+func (pl *Poller) pollRemove(roomId string) string {
+	pl.mutex.Lock()
+	defer pl.unlock()
 
-	if _, ok := polls[roomId]; !ok {
+	poll, ok := pl.polls[roomId]
+	if !ok {
 		return "There is no poll."
 	}
 
-	delete(polls, roomId)
+	poll.stopTimers()
+	if poll.IsActive {
+		pl.pinMessage(roomId, poll, false)
+	}
+	delete(pl.polls, roomId)
 
 	return "Poll removed."
 }
 
-func pollAddOption(roomId, option string) string {
-	mutex.Lock()
-	defer mutex.Unlock()
+func (pl *Poller) pollDescribe(roomId, description string) string {
+	pl.mutex.Lock()
+	defer pl.unlock()
+
+	poll, ok := pl.polls[roomId]
+	if !ok {
+		return "There is no poll."
+	}
+
+	poll.Description = strings.TrimSpace(description)
+	if poll.Description == "" {
+		return "Description cleared."
+	}
+	return fmt.Sprintf("Description set: %s", poll.Description)
+}
+
+func (pl *Poller) pollAddOption(roomId, userId, option string) string {
+	pl.mutex.Lock()
+	defer pl.unlock()
 
-	poll, ok := polls[roomId]
+	poll, ok := pl.polls[roomId]
 	if !ok {
 		return "There is no poll."
 	}
+	if poll.OptionsLocked && !pl.canManage(roomId, userId, poll) {
+		return "Options are locked, only the creator of the poll can add options."
+	}
+
+	op, err := pl.addOption(roomId, poll, option)
+	if err != nil {
+		return err.Error()
+	}
+	return fmt.Sprintf("Added as option %d: %s", len(poll.Options), op.label())
+}
+
+// pollAddOptions adds several options at once, separated by the room's
+// delimiter.
+func (pl *Poller) pollAddOptions(roomId, userId, options string) string {
+	pl.mutex.Lock()
+	defer pl.unlock()
+
+	poll, ok := pl.polls[roomId]
+	if !ok {
+		return "There is no poll."
+	}
+	if poll.OptionsLocked && !pl.canManage(roomId, userId, poll) {
+		return "Options are locked, only the creator of the poll can add options."
+	}
+
+	var added []string
+	for _, option := range pl.splitArgs(roomId, options) {
+		op, err := pl.addOption(roomId, poll, option)
+		if err != nil {
+			return fmt.Sprintf("%s\nAdded options: %s", err.Error(), strings.Join(added, ", "))
+		}
+		added = append(added, fmt.Sprintf("%d. %s", len(poll.Options), op.label()))
+	}
+	return fmt.Sprintf("Added options: %s", strings.Join(added, ", "))
+}
 
+// addOption adds an option, given as "<text> [=alias] [@url]", to the poll,
+// normalizing its text as set by the room's options.case pref. It must be
+// called with the mutex held.
+func (pl *Poller) addOption(roomId string, poll *pollEntry, option string) (pollOption, error) {
+	words := strings.Fields(option)
 	op := pollOption{
 		Text:  option,
 		Votes: 0,
 	}
+	for len(words) > 1 {
+		last := words[len(words)-1]
+		if len(last) > 1 && last[0] == '=' && op.Alias == "" {
+			op.Alias = last[1:]
+		} else if strings.HasPrefix(last, "@") && strings.Contains(last, "://") && op.URL == "" {
+			op.URL = last[1:]
+		} else {
+			break
+		}
+		words = words[:len(words)-1]
+	}
+	if op.Alias != "" || op.URL != "" {
+		op.Text = strings.Join(words, " ")
+	}
+	if pl.pref(roomId, "options.case", "") == "title" {
+		if title := titleCase(op.Text); title != op.Text {
+			op.Original, op.Text = op.Text, title
+		}
+	}
+
+	if op.Alias != "" {
+		if _, err := strconv.Atoi(op.Alias); err == nil {
+			return op, errors.New("An alias cannot be a number.")
+		}
+		for _, o := range poll.Options {
+			if strings.EqualFold(o.Alias, op.Alias) {
+				return op, fmt.Errorf("The alias '%s' is already used by %s.", op.Alias, o.Text)
+			}
+		}
+	}
+	if op.URL != "" {
+		if u, err := url.ParseRequestURI(op.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return op, fmt.Errorf("'%s' is not a valid http or https URL.", op.URL)
+		}
+	}
 	poll.Options = append(poll.Options, op)
-	return fmt.Sprintf("Added option: %s", op.Text)
+	return op, nil
 }
 
-func pollStart(roomId string) string {
-	mutex.Lock()
-	defer mutex.Unlock()
+func (pl *Poller) pollLockOptions(roomId, userId string, locked bool) string {
+	pl.mutex.Lock()
+	defer pl.unlock()
 
-	poll, ok := polls[roomId]
+	poll, ok := pl.polls[roomId]
+	if !ok {
+		return "There is no poll."
+	}
+	if !pl.canManage(roomId, userId, poll) {
+		return "Only the creator of the poll or a poll admin can lock or unlock options."
+	}
+
+	poll.OptionsLocked = locked
+	if locked {
+		return "Options locked, only the creator of the poll can add options."
+	}
+	return "Options unlocked, everybody can add options."
+}
+
+// findOption returns the index of the option of the room's poll with the
+// choice as its alias or text, or 0 if there is none.
+func (pl *Poller) findOption(roomId, choice string) int {
+	pl.mutex.RLock()
+	defer pl.mutex.RUnlock()
+
+	poll, ok := pl.polls[roomId]
+	if !ok {
+		return 0
+	}
+	for k, o := range poll.Options {
+		if o.Alias != "" && strings.EqualFold(o.Alias, choice) {
+			return k + 1
+		}
+	}
+	key := optionKey(choice)
+	for k, o := range poll.Options {
+		if optionKey(o.Text) == key {
+			return k + 1
+		}
+	}
+	return 0
+}
+
+func (pl *Poller) pollMove(roomId string, from, to int) string {
+	pl.mutex.Lock()
+	defer pl.unlock()
+
+	poll, ok := pl.polls[roomId]
+	if !ok {
+		return "There is no poll."
+	}
+	if poll.IsActive {
+		return "Options cannot be moved while the poll is running."
+	}
+	if from <= 0 || from > len(poll.Options) || to <= 0 || to > len(poll.Options) {
+		return fmt.Sprintf("Please choose numbers between 1 to %d", len(poll.Options))
+	}
+
+	op := poll.Options[from-1]
+	poll.Options = append(poll.Options[:from-1], poll.Options[from:]...)
+	poll.Options = append(poll.Options[:to-1], append([]pollOption{op}, poll.Options[to-1:]...)...)
+	// the answer of a quiz follows its option
+	switch {
+	case poll.Answer == from:
+		poll.Answer = to
+	case from < poll.Answer && poll.Answer <= to:
+		poll.Answer--
+	case to <= poll.Answer && poll.Answer < from:
+		poll.Answer++
+	}
+
+	return fmt.Sprintf("Moved option: %s\n%s", op.Text, poll.viewedBy("").Result())
+}
+
+func (pl *Poller) pollStart(roomId string) string {
+	pl.mutex.Lock()
+	defer pl.unlock()
+
+	poll, ok := pl.polls[roomId]
 	if !ok {
 		return "There is no poll."
 	}
 	if poll.IsActive {
 		return "The poll is currently running."
 	}
-	if len(poll.Options) < 2 {
+	if len(poll.Options) < poll.minOptions() {
+		if poll.MinOptions > 0 {
+			return fmt.Sprintf("The poll needs at least %d options, %d more to go. Use !poll option <option> to add options.",
+				poll.MinOptions, poll.MinOptions-len(poll.Options))
+		}
 		return "Use !poll option <option> to add options."
 	}
+	if poll.Winners > len(poll.Options) {
+		return fmt.Sprintf("The poll reports %d winners but has only %d options. Use !poll option <option> to add options.",
+			poll.Winners, len(poll.Options))
+	}
+	if distinct, duplicates := poll.distinctOptions(); distinct < 2 {
+		return fmt.Sprintf("The poll needs at least two distinct options, %s. Use !poll option <option> to add options.",
+			strings.Join(duplicates, ", "))
+	}
+	if len(poll.Verbs) > 0 && len(poll.Verbs) != len(poll.Options) {
+		return fmt.Sprintf("The poll has %d verbs for %d options, one verb per option is needed.",
+			len(poll.Verbs), len(poll.Options))
+	}
 
-	poll.IsActive = true
+	pl.activate(roomId, poll)
 
-	return fmt.Sprintf("Poll:\n%s", poll.Result())
+	return fmt.Sprintf("Poll:\n%s", poll.viewedBy("").Details())
 }
 
-func pollEnd(roomId string) string {
-	mutex.Lock()
-	defer mutex.Unlock()
+// activate starts the poll. It must be called with the mutex held.
+func (pl *Poller) activate(roomId string, poll *pollEntry) {
+	poll.IsActive = true
+	poll.StartedAt = now()
+	poll.stopNudge()
+	pl.armDeadline(roomId, poll)
+	pl.armSnapshots(roomId, poll)
+	pl.armCountdown(roomId, poll)
+	pl.pinMessage(roomId, poll, true)
+}
 
-	poll, ok := polls[roomId]
+// pollEnd ends the poll and returns its final results, or the reason it
+// could not be ended along with false. from is the room asking, which
+// replies with the results.
+func (pl *Poller) pollEnd(roomId, from string) (string, bool) {
+	pl.mutex.Lock()
+	defer pl.unlock()
+
+	poll, ok := pl.polls[roomId]
 	if !ok {
-		return "There is no poll."
+		return "There is no poll.", false
 	}
 	if !poll.IsActive {
-		return "There is no active poll."
+		return "There is no active poll.", false
 	}
 
-	delete(polls, roomId)
+	return pl.endPoll(roomId, poll, from), true
+}
+
+// endPoll closes the poll and returns its final results, which the caller
+// replies to the room from. It must be called with the mutex held.
+func (pl *Poller) endPoll(roomId string, poll *pollEntry, from string) string {
+	poll.stopTimers()
+	pl.pinMessage(roomId, poll, false)
+	// late votes, e.g. reactions, are refused by anything still holding it
+	poll.IsActive = false
+	delete(pl.polls, roomId)
+	pl.retainPoll(roomId, poll)
 
-	return fmt.Sprintf("Poll finished, final results:\n%s", poll.Result())
+	results := fmt.Sprintf("Poll finished, final results:\n%s", poll.result(pl.resultLimit(roomId)))
+	if poll.Winners > 0 {
+		results = fmt.Sprintf("%s\nTop %d:\n%s", results, poll.Winners, poll.Ranking(poll.Winners))
+	}
+	if poll.Raffle {
+		results = fmt.Sprintf("%s\n%s", results, poll.draw())
+	} else if tie := poll.breakTie(); tie != "" {
+		results = fmt.Sprintf("%s\n%s", results, tie)
+	}
+	if close := poll.closeResult(pl.closeMargin(roomId)); close != "" {
+		results = fmt.Sprintf("%s\n%s", results, close)
+	}
+	if line := pl.quorumLine(roomId, poll); line != "" {
+		results = fmt.Sprintf("%s\n%s", results, line)
+	}
+	if poll.Quiz {
+		results = fmt.Sprintf("%s\n%s", results, poll.quizResults())
+	}
+	if poll.Open && len(poll.Ballots) > 0 {
+		results = fmt.Sprintf("%s\nVotes: %s", results, poll.Reveal())
+	}
+	if next := pl.startNext(roomId, poll); next != "" {
+		results = fmt.Sprintf("%s\n%s", results, next)
+	}
+	pl.broadcastResults(roomId, poll, from, results)
+	return results
 }
 
-func pollVote(roomId, userId string, index int) string {
-	mutex.Lock()
-	defer mutex.Unlock()
+// Vote casts the vote of the user for the option at index in the room's
+// poll. It returns ErrNoPoll, ErrNotActive, ErrBudgetPoll, ErrNotEligible,
+// ErrNotAbstainer, ErrInvalidIndex, ErrThrottled, ErrCooldown,
+// ErrAlreadyVoted, ErrVotesLocked or ErrAckRequired when the vote is refused,
+// or the error of a vote validator.
+func Vote(roomId, userId string, index int) error {
+	return defaultPoller.Vote(roomId, userId, index)
+}
+
+// Vote casts the vote of the user in the room's poll of the instance, see
+// Vote.
+func (pl *Poller) Vote(roomId, userId string, index int) error {
+	pl.fetchMembers(roomId)
+	pl.mutex.Lock()
+	defer pl.unlock()
+
+	_, err := pl.castVote(roomId, userId, "", index)
+	return err
+}
+
+func (pl *Poller) pollVote(roomId, userId, userName string, index int) string {
+	pl.mutex.Lock()
+	defer pl.unlock()
+
+	poll, err := pl.castVote(roomId, userId, userName, index)
+	if errors.Is(err, ErrCooldown) {
+		return ""
+	}
+	if err != nil {
+		return errorMessage(err)
+	}
+	delegated := ""
+	if cast := pl.castDelegated(roomId, poll, userId, userName, index); len(cast) > 0 {
+		delegated = fmt.Sprintf("\nAlso voted on behalf of %s.", strings.Join(cast, ", "))
+	}
+
+	if poll.Quiet || poll.editsMessage() || pl.pref(roomId, "quiet", "false") == "true" {
+		return fmt.Sprintf("Vote recorded for %s%s", poll.Options[index-1].Text, delegated)
+	}
+	return fmt.Sprintf("Poll:\n%s%s", poll.viewedBy(userId).Result(), delegated)
+}
 
-	poll, ok := polls[roomId]
+// vote casts the vote of the user of the event in the poll of the room and
+// replies to the user.
+func (pl *Poller) vote(evt hal.Evt, roomId string, index int) {
+	if msg := pl.pollVote(roomId, evt.UserId, evt.User, index); msg != "" {
+		pl.replyVote(evt, msg)
+	}
+	pl.refreshMessage(evt)
+}
+
+// verbIndex returns the index of the option the command is the vote verb of,
+// as set with !poll new -verbs, or 0 if it is none.
+func (pl *Poller) verbIndex(roomId, command string) int {
+	pl.mutex.RLock()
+	defer pl.mutex.RUnlock()
+
+	poll, ok := pl.polls[roomId]
 	if !ok {
-		return "There is no poll."
+		return 0
+	}
+	return poll.verbIndex(command)
+}
+
+// verbIndex returns the index of the option of the poll the command is the
+// vote verb of, or 0 if it is none.
+func (p pollEntry) verbIndex(command string) int {
+	for k, verb := range p.Verbs {
+		if strings.EqualFold(verb, command) {
+			return k + 1
+		}
+	}
+	return 0
+}
+
+// voteCooldown returns how long repeated votes of a user are ignored for, as
+// set by the room's vote.cooldown pref.
+func (pl *Poller) voteCooldown(roomId string) time.Duration {
+	d, err := time.ParseDuration(pl.pref(roomId, "vote.cooldown", "2s"))
+	if err != nil || d < 0 {
+		return 2 * time.Second
+	}
+	return d
+}
+
+// castVote records the vote of the user for the option at index in the
+// room's poll. It must be called with the mutex held, which keeps a revote
+// from racing with another vote of the same user.
+func (pl *Poller) castVote(roomId, userId, userName string, index int) (poll *pollEntry, err error) {
+	defer func() { pl.logVote(roomId, userId, poll, index, err) }()
+
+	poll, err = pl.activePoll(roomId)
+	if err != nil {
+		return nil, err
+	}
+	if poll.Budget > 0 {
+		return nil, ErrBudgetPoll
+	}
+	now := now()
+	hasVoted, err := pl.checkVote(roomId, poll, userId, userName, now, index)
+	if err != nil {
+		return nil, err
+	}
+	if !poll.acked(userId) {
+		return nil, poll.hold(userId, index)
+	}
+
+	if hasVoted {
+		// change the vote, which is allowed until the votes are locked
+		for k := range poll.Ballots {
+			if b := &poll.Ballots[k]; b.UserId == userId {
+				poll.Options[b.Option].Votes -= 1
+				b.Option = index - 1
+				break
+			}
+		}
+	} else {
+		poll.HasVoted = append(poll.HasVoted, userId)
+		poll.Ballots = append(poll.Ballots, ballot{UserId: userId, UserName: userName, Option: index - 1})
+	}
+	poll.Options[index-1].Votes += 1
+	pl.afterVote(roomId, poll, userId, now, index)
+
+	return poll, nil
+}
+
+// activePoll returns the running poll of the room. It must be called with
+// the mutex held.
+func (pl *Poller) activePoll(roomId string) (*pollEntry, error) {
+	poll, ok := pl.polls[roomId]
+	if !ok {
+		return nil, ErrNoPoll
 	}
 	if !poll.IsActive {
-		return "There is no active poll. Use !poll start to start the poll."
+		return nil, ErrNotActive
 	}
-	if index <= 0 || index > len(poll.Options) {
-		return fmt.Sprintf("Please choose a number between 1 to %d", len(poll.Options))
+	return poll, nil
+}
+
+// checkVote returns why the user may not vote at now for the options at
+// indices of the running poll, if anything, and whether the user voted
+// before, in which case the vote changes. It must be called with the mutex
+// held.
+func (pl *Poller) checkVote(roomId string, poll *pollEntry, userId, userName string, now time.Time, indices ...int) (bool, error) {
+	if !poll.CanVote(userId, userName) {
+		return false, ErrNotEligible
 	}
-	hasVoted := false
-	for _, uId := range poll.HasVoted {
-		if userId == uId {
-			hasVoted = true
-			break
+	if !poll.abstained(userId) {
+		return false, ErrNotAbstainer
+	}
+	if err := poll.checkTenure(roomId, userId); err != nil {
+		return false, err
+	}
+	for _, index := range indices {
+		if index <= 0 || index > len(poll.Options) {
+			return false, rangeError{index: index, max: len(poll.Options)}
 		}
 	}
-	if hasVoted {
-		return "You have already voted."
+	if err := pl.throttle(roomId, poll, now); err != nil {
+		return false, err
+	}
+	if last, ok := poll.lastVote[userId]; ok && now.Sub(last) < pl.voteCooldown(roomId) {
+		return false, ErrCooldown
+	}
+	hasVoted := poll.voted(userId)
+	if hasVoted && poll.LockVotes == 0 {
+		return false, ErrAlreadyVoted
 	}
+	if hasVoted && !now.Before(poll.StartedAt.Add(poll.LockVotes)) {
+		return false, ErrVotesLocked
+	}
+	for _, index := range indices {
+		if err := validateVote(roomId, userId, index); err != nil {
+			return false, err
+		}
+	}
+	return hasVoted, nil
+}
 
-	poll.Options[index-1].Votes += 1
-	poll.HasVoted = append(poll.HasVoted, userId)
+// afterVote records in the timeline the vote of the user for the options at
+// indices, which the tally already counts, and announces what the vote
+// caused: a pushed deadline, the quorum, the thresholds reached and the end
+// of a poll everybody voted in. It must be called with the mutex held.
+func (pl *Poller) afterVote(roomId string, poll *pollEntry, userId string, now time.Time, indices ...int) {
+	for _, index := range indices {
+		poll.Timeline = append(poll.Timeline, voteRecord{Option: index - 1, Time: now})
+	}
+	if len(poll.Timeline) > maxTimeline {
+		poll.Timeline = poll.Timeline[len(poll.Timeline)-maxTimeline:]
+	}
+	if poll.lastVote == nil {
+		poll.lastVote = make(map[string]time.Time)
+	}
+	poll.lastVote[userId] = now
+	pl.checkTally(roomId, poll)
+	pl.preventSniping(roomId, poll, now)
+	pl.announceQuorum(roomId, poll)
+	pl.crossThresholds(roomId, poll)
+	pl.closeIfAllVoted(roomId, poll)
+}
 
-	return fmt.Sprintf("Poll:\n%s", poll.Result())
+func (pl *Poller) pollTimeline(roomId, userId string) string {
+	pl.mutex.RLock()
+	defer pl.mutex.RUnlock()
+
+	poll, ok := pl.polls[roomId]
+	if !ok {
+		return "There is no poll."
+	}
+	if poll.hidesResults(userId) {
+		return poll.hiddenMessage()
+	}
+	if len(poll.Timeline) == 0 && len(poll.Snapshots) == 0 {
+		return "There are no votes yet."
+	}
+
+	lines := make([]string, 0, len(poll.Timeline))
+	for _, v := range poll.Timeline {
+		lines = append(lines, fmt.Sprintf(" %s %d. %s", v.Time.Format(time.RFC3339), v.Option+1, poll.Options[v.Option].Text))
+	}
+	if len(poll.Snapshots) > 0 {
+		lines = append(lines, "Snapshots:")
+		lines = append(lines, poll.snapshotLines()...)
+	}
+	return fmt.Sprintf("Timeline of %s:\n%s", poll.viewedBy("").Title, strings.Join(lines, "\n"))
 }
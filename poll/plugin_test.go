@@ -0,0 +1,613 @@
+package poll
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDescribe(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.run("room", "alice", "!poll new Lunch?")
+	tp.run("room", "alice", "!poll describe Where do we eat on Friday?")
+	tp.run("room", "alice", "!poll option Pizza")
+	tp.run("room", "alice", "!poll option Tacos")
+	if reply := tp.run("room", "alice", "!poll start"); !strings.HasPrefix(reply, "Poll:\nLunch?\nWhere do we eat on Friday?\n") {
+		t.Errorf("start = %q, want the description under the title", reply)
+	}
+
+	if reply := tp.run("room", "bob", "!poll show"); !strings.Contains(reply, "Where do we eat on Friday?") {
+		t.Errorf("show = %q, want the description", reply)
+	}
+	tp.run("room", "alice", "!poll describe The team lunch")
+	if reply := tp.run("room", "bob", "!poll show"); !strings.Contains(reply, "The team lunch") || strings.Contains(reply, "Friday") {
+		t.Errorf("show = %q, want the new description only", reply)
+	}
+	if reply := tp.run("room", "alice", "!poll describe"); reply != "Description cleared." {
+		t.Errorf("clearing the description reply = %q", reply)
+	}
+}
+
+func TestResultLimit(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.prefs["room/result.limit"] = "3"
+	tp.start("room", "", "Pizza", "Tacos", "Sushi", "Curry", "Salad")
+	tp.castVotes("room", 2, 2, 4, 4, 4, 5)
+
+	want := " 4. Curry (3 votes)\n 2. Tacos (2 votes)\n 5. Salad (1 votes)\n ...and 2 more options"
+	if reply := tp.run("room", "bob", "!poll show"); !strings.HasSuffix(reply, want) {
+		t.Errorf("show = %q, want the top three and the summary line", reply)
+	}
+	if reply := tp.run("room", "alice", "!poll end"); !strings.HasSuffix(reply, want) {
+		t.Errorf("results = %q, want the top three and the summary line", reply)
+	}
+}
+
+func TestAllowlist(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.start("room", "-allow=@bob,@carol", "Pizza", "Tacos")
+
+	if reply := tp.run("room", "bob", "!poll vote 1"); !strings.HasPrefix(reply, "Poll:") {
+		t.Errorf("vote of an allowed user reply = %q", reply)
+	}
+	if reply := tp.run("room", "alice", "!poll vote 1"); !strings.HasPrefix(reply, "Poll:") {
+		t.Errorf("vote of the creator reply = %q", reply)
+	}
+	if reply := tp.run("room", "dave", "!poll vote 1"); reply != "You are not eligible to vote in this poll." {
+		t.Errorf("vote of a user not on the allowlist reply = %q", reply)
+	}
+	if got, want := tp.votes("room"), []int{2, 0}; !reflect.DeepEqual(got, want) {
+		t.Errorf("votes = %v, want %v", got, want)
+	}
+}
+
+func TestStartRefusesDuplicateOptions(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.run("room", "alice", "!poll new Ship it?")
+	tp.run("room", "alice", "!poll option Yes")
+	tp.run("room", "alice", "!poll option yes")
+
+	want := "The poll needs at least two distinct options, 'yes' is the same as 'Yes'. Use !poll option <option> to add options."
+	if reply := tp.run("room", "alice", "!poll start"); reply != want {
+		t.Errorf("start reply = %q, want %q", reply, want)
+	}
+	tp.run("room", "alice", "!poll option No")
+	if reply := tp.run("room", "alice", "!poll start"); !strings.HasPrefix(reply, "Poll:") {
+		t.Errorf("start with a distinct option reply = %q", reply)
+	}
+}
+
+func TestTimeline(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.start("room", "", "Pizza", "Tacos")
+	tp.run("room", "bob", "!poll vote 2")
+	tp.clock.Advance(90 * time.Second)
+	tp.run("room", "carol", "!poll vote 1")
+
+	want := "Timeline of Lunch?:\n 2024-03-04T10:00:00Z 2. Tacos\n 2024-03-04T10:01:30Z 1. Pizza"
+	if reply := tp.run("room", "bob", "!poll timeline"); reply != want {
+		t.Errorf("timeline = %q, want %q", reply, want)
+	}
+}
+
+func TestTimelineCapped(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.start("room", "", "Pizza", "Tacos")
+
+	tp.mutex.Lock()
+	poll := tp.polls["room"]
+	for k := 0; k < maxTimeline; k++ {
+		poll.Timeline = append(poll.Timeline, voteRecord{Option: 1, Time: tp.clock.Now()})
+	}
+	tp.mutex.Unlock()
+	if err := tp.Vote("room", "bob", 1); err != nil {
+		t.Fatalf("Vote failed: %v", err)
+	}
+
+	tp.mutex.RLock()
+	defer tp.mutex.RUnlock()
+	if len(poll.Timeline) != maxTimeline {
+		t.Fatalf("timeline holds %d votes, want %d", len(poll.Timeline), maxTimeline)
+	}
+	if last := poll.Timeline[len(poll.Timeline)-1]; last.Option != 0 {
+		t.Errorf("last vote of the timeline is for option %d, want the vote just cast", last.Option+1)
+	}
+}
+
+func TestNewForce(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.start("room", "", "Pizza", "Tacos")
+	tp.run("room", "bob", "!poll vote 1")
+
+	want := "The poll 'Lunch?' (active, 1 votes) already exists.\nUse !poll remove to remove it, or !poll new -force <title> to replace it."
+	if reply := tp.run("room", "alice", "!poll new Dinner?"); reply != want {
+		t.Errorf("new over a poll reply = %q, want %q", reply, want)
+	}
+	if got := tp.votes("room"); !reflect.DeepEqual(got, []int{1, 0}) {
+		t.Errorf("votes of the existing poll = %v, want them kept", got)
+	}
+
+	want = "Poll 'Lunch?' replaced.\nPoll 'Dinner?' created.\nUse !poll option <option> to add options."
+	if reply := tp.run("room", "alice", "!poll new -force Dinner?"); reply != want {
+		t.Errorf("new -force reply = %q, want %q", reply, want)
+	}
+	if reply := tp.run("room", "alice", "!poll show"); reply != "Poll (Inactive):\nDinner?\n\nNeeds 2 more options." {
+		t.Errorf("show after the replacement = %q", reply)
+	}
+}
+
+func TestWinnersTiedAtTheBoundary(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.start("room", "-winners=2", "Pizza", "Tacos", "Sushi", "Curry")
+	tp.castVotes("room", 3, 3, 3, 1, 1, 2, 2, 4)
+
+	want := "\nTop 2:\n #1 Sushi (3 votes)\n #2 Pizza (2 votes)\n #2 Tacos (2 votes)"
+	if reply := tp.run("room", "alice", "!poll end"); !strings.Contains(reply, want) {
+		t.Errorf("results = %q, want the options tied for second place ranked", reply)
+	}
+}
+
+func TestWinnersOverOptions(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.run("room", "alice", "!poll new -winners=3 Lunch?")
+	tp.run("room", "alice", "!poll option Pizza")
+	tp.run("room", "alice", "!poll option Tacos")
+
+	want := "The poll reports 3 winners but has only 2 options. Use !poll option <option> to add options."
+	if reply := tp.run("room", "alice", "!poll start"); reply != want {
+		t.Errorf("start reply = %q, want %q", reply, want)
+	}
+}
+
+func TestMove(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.run("room", "alice", "!poll new Lunch?")
+	for _, o := range []string{"Pizza", "Tacos", "Sushi"} {
+		tp.run("room", "alice", "!poll option "+o)
+	}
+
+	want := "Moved option: Sushi\nLunch?\n 1. Sushi (0 votes)\n 2. Pizza (0 votes)\n 3. Tacos (0 votes)"
+	if reply := tp.run("room", "alice", "!poll move 3 1"); reply != want {
+		t.Errorf("move reply = %q, want %q", reply, want)
+	}
+	if reply := tp.run("room", "alice", "!poll move 0 4"); reply != "Please choose numbers between 1 to 3" {
+		t.Errorf("move out of range reply = %q", reply)
+	}
+	tp.run("room", "alice", "!poll start")
+	if reply := tp.run("room", "alice", "!poll move 1 2"); reply != "Options cannot be moved while the poll is running." {
+		t.Errorf("move of a running poll reply = %q", reply)
+	}
+}
+
+func TestQuietVotes(t *testing.T) {
+	for _, tt := range []struct {
+		name, flags string
+		prefs       map[string]string
+	}{
+		{"flag", "-quiet", nil},
+		{"pref", "", map[string]string{"room/quiet": "true"}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			tp := newTestPoller(t)
+			for k, v := range tt.prefs {
+				tp.prefs[k] = v
+			}
+			tp.start("room", tt.flags, "Pizza", "Tacos")
+
+			if reply := tp.run("room", "bob", "!poll vote 1"); reply != "Vote recorded for Pizza" {
+				t.Errorf("vote reply = %q, want the confirmation alone", reply)
+			}
+			if reply := tp.run("room", "bob", "!poll show"); !strings.Contains(reply, "Pizza (1 votes)") {
+				t.Errorf("show = %q, want the results", reply)
+			}
+		})
+	}
+}
+
+func TestStartRefusesDuplicateUnicodeForms(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.run("room", "alice", "!poll new Coffee?")
+	tp.run("room", "alice", "!poll option café")
+	tp.run("room", "alice", "!poll option café")
+
+	want := "The poll needs at least two distinct options, 'café' is the same as 'café'. Use !poll option <option> to add options."
+	if reply := tp.run("room", "alice", "!poll start"); reply != want {
+		t.Errorf("start reply = %q, want %q", reply, want)
+	}
+}
+
+func TestAllowlistResolvesHandles(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.via = directoryBroker{tp.broker, map[string]string{"bob": "U2"}}
+	tp.start("room", "-allow=@bob,@zed", "Pizza", "Tacos")
+
+	tp.mutex.RLock()
+	allow := tp.polls["room"].Allow
+	tp.mutex.RUnlock()
+	if want := []string{"U2", "zed"}; !reflect.DeepEqual(allow, want) {
+		t.Errorf("allowlist = %q, want %q", allow, want)
+	}
+	if reply := tp.run("room", "U2", "!poll vote 1"); !strings.HasPrefix(reply, "Poll:") {
+		t.Errorf("vote of a resolved user reply = %q", reply)
+	}
+}
+
+func TestOptionAliases(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.run("room", "alice", "!poll new Release?")
+	if reply := tp.run("room", "alice", "!poll option Deploy on Friday =fri"); reply != "Added as option 1: Deploy on Friday [fri]" {
+		t.Errorf("option with an alias reply = %q", reply)
+	}
+	if reply := tp.run("room", "alice", "!poll option Deploy on Monday =FRI"); reply != "The alias 'FRI' is already used by Deploy on Friday." {
+		t.Errorf("option with a duplicate alias reply = %q", reply)
+	}
+	if reply := tp.run("room", "alice", "!poll option Deploy on Monday =2"); reply != "An alias cannot be a number." {
+		t.Errorf("option with a numeric alias reply = %q", reply)
+	}
+	tp.run("room", "alice", "!poll option Deploy on Monday =mon")
+	tp.run("room", "alice", "!poll start")
+
+	tp.run("room", "bob", "!poll vote fri")
+	tp.run("room", "carol", "!poll vote deploy on monday")
+	if got, want := tp.votes("room"), []int{1, 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("votes = %v, want %v", got, want)
+	}
+}
+
+func TestOptionsDelimiter(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.run("room", "alice", "!poll new Lunch?")
+	if reply := tp.run("room", "alice", "!poll options Pizza | Tacos ||"); reply != "Added options: 1. Pizza, 2. Tacos" {
+		t.Errorf("options reply = %q", reply)
+	}
+
+	tp.prefs["room/delimiter"] = ";"
+	if reply := tp.run("room", "alice", "!poll options Fish | Chips; Salad"); reply != "Added options: 3. Fish | Chips, 4. Salad" {
+		t.Errorf("options with the ; delimiter reply = %q", reply)
+	}
+}
+
+func TestMinOptions(t *testing.T) {
+	tp := newTestPoller(t)
+	if reply := tp.run("room", "alice", "!poll new -min=1 Lunch?"); reply != "-min must be an integer of at least 2." {
+		t.Errorf("new with -min=1 reply = %q", reply)
+	}
+	tp.run("room", "alice", "!poll new -min=3 Lunch?")
+	tp.run("room", "alice", "!poll option Pizza")
+
+	if reply := tp.run("room", "bob", "!poll show"); !strings.HasSuffix(reply, "\nNeeds 2 more options.") {
+		t.Errorf("show = %q, want the shortfall", reply)
+	}
+	tp.run("room", "alice", "!poll option Tacos")
+	want := "The poll needs at least 3 options, 1 more to go. Use !poll option <option> to add options."
+	if reply := tp.run("room", "alice", "!poll start"); reply != want {
+		t.Errorf("start reply = %q, want %q", reply, want)
+	}
+	if reply := tp.run("room", "bob", "!poll show"); !strings.HasSuffix(reply, "\nNeeds 1 more options.") {
+		t.Errorf("show = %q, want the shortfall", reply)
+	}
+	tp.run("room", "alice", "!poll option Sushi")
+	if reply := tp.run("room", "alice", "!poll start"); !strings.HasPrefix(reply, "Poll:") {
+		t.Errorf("start with enough options reply = %q", reply)
+	}
+}
+
+func TestOpenReveal(t *testing.T) {
+	for _, tt := range []struct {
+		flags  string
+		reveal bool
+	}{{"-open", true}, {"", false}} {
+		tp := newTestPoller(t)
+		tp.start("room", tt.flags, "Pizza", "Tacos")
+		tp.run("room", "alice", "!poll vote 1")
+		tp.run("room", "bob", "!poll vote 2")
+
+		reply := tp.run("room", "alice", "!poll end")
+		if got := strings.Contains(reply, "\nVotes: alice→Pizza, bob→Tacos"); got != tt.reveal {
+			t.Errorf("results of a poll created with %q = %q, want the votes revealed: %v", tt.flags, reply, tt.reveal)
+		}
+		if !tt.reveal && strings.Contains(reply, "alice") {
+			t.Errorf("results of an anonymous poll = %q, want no voters", reply)
+		}
+	}
+}
+
+func TestOptionLinks(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.run("room", "alice", "!poll new Which logo?")
+
+	for _, option := range []string{"Logo A @ftp://example.com/a.png", "Logo A @https://"} {
+		if reply := tp.run("room", "alice", "!poll option "+option); !strings.HasSuffix(reply, "is not a valid http or https URL.") {
+			t.Errorf("option %q reply = %q, want it refused", option, reply)
+		}
+	}
+	if reply := tp.run("room", "alice", "!poll option Logo A @https://example.com/a.png"); reply != "Added as option 1: Logo A <https://example.com/a.png>" {
+		t.Errorf("option with a link reply = %q", reply)
+	}
+	tp.run("room", "alice", "!poll option Logo B =b @https://example.com/b.png")
+	want := "Poll (Inactive):\nWhich logo?\n 1. Logo A <https://example.com/a.png> (0 votes)\n 2. Logo B [b] <https://example.com/b.png> (0 votes)"
+	if reply := tp.run("room", "bob", "!poll show"); reply != want {
+		t.Errorf("show = %q, want %q", reply, want)
+	}
+}
+
+func TestVoteRepeatedWithinCooldown(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.start("room", "-lockvotes=1h", "Pizza", "Tacos")
+
+	tp.run("room", "bob", "!poll vote 1")
+	tp.clock.Advance(time.Second)
+	if reply := tp.run("room", "bob", "!poll vote 2"); reply != "" {
+		t.Errorf("repeated vote reply = %q, want none", reply)
+	}
+	if got, want := tp.votes("room"), []int{1, 0}; !reflect.DeepEqual(got, want) {
+		t.Errorf("votes after the repeated vote = %v, want %v", got, want)
+	}
+	tp.clock.Advance(time.Second)
+	tp.run("room", "bob", "!poll vote 2")
+	if got, want := tp.votes("room"), []int{0, 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("votes after the change = %v, want %v", got, want)
+	}
+}
+
+func TestLockVotes(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.start("room", "-lockvotes=1h", "Pizza", "Tacos")
+
+	tp.run("room", "bob", "!poll vote 1")
+	tp.clock.Advance(30 * time.Minute)
+	tp.run("room", "bob", "!poll vote 2")
+	if got, want := tp.votes("room"), []int{0, 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("votes after the change within the window = %v, want %v", got, want)
+	}
+
+	tp.clock.Advance(30 * time.Minute)
+	if reply := tp.run("room", "bob", "!poll vote 1"); reply != "You have already voted, and votes can no longer be changed." {
+		t.Errorf("vote after the window reply = %q", reply)
+	}
+	if got, want := tp.votes("room"), []int{0, 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("votes after the window = %v, want %v", got, want)
+	}
+}
+
+func TestVoteAliases(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.start("room", "", "Pizza", "Tacos")
+
+	tp.run("room", "bob", "!poll +1 2")
+	if got, want := tp.votes("room"), []int{0, 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("votes = %v, want %v", got, want)
+	}
+
+	tp.prefs["room/vote.aliases"] = "yay, aye"
+	tp.run("room", "carol", "!poll aye 1")
+	tp.run("room", "dave", "!poll +1 1")
+	if got, want := tp.votes("room"), []int{1, 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("votes with the aliases pref = %v, want %v", got, want)
+	}
+}
+
+func TestConcurrentAddOptions(t *testing.T) {
+	const adds = 20
+	tp := newTestPoller(t)
+	tp.run("room", "alice", "!poll new Lunch?")
+
+	replies := make([]string, adds)
+	var wg sync.WaitGroup
+	for k := range replies {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			replies[k] = tp.pollAddOption("room", "alice", fmt.Sprintf("Option %d", k))
+		}()
+	}
+	wg.Wait()
+
+	tp.mutex.RLock()
+	defer tp.mutex.RUnlock()
+	options := tp.polls["room"].Options
+	seen := make(map[int]bool)
+	for k, reply := range replies {
+		var index int
+		var text string
+		if _, err := fmt.Sscanf(reply, "Added as option %d: Option %s", &index, &text); err != nil {
+			t.Fatalf("reply %q: %v", reply, err)
+		}
+		if seen[index] {
+			t.Errorf("index %d reported twice", index)
+		}
+		seen[index] = true
+		if got, want := options[index-1].Text, fmt.Sprintf("Option %d", k); got != want {
+			t.Errorf("option %d = %q, want %q as reported", index, got, want)
+		}
+	}
+}
+
+func TestOptionsTitleCase(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.prefs["room/options.case"] = "title"
+	tp.run("room", "alice", "!poll new Lunch?")
+
+	if reply := tp.run("room", "alice", "!poll option pizza place"); reply != "Added as option 1: Pizza Place" {
+		t.Errorf("option reply = %q", reply)
+	}
+	tp.run("room", "alice", "!poll option NYC deli")
+	if reply := tp.run("room", "bob", "!poll index"); !strings.HasSuffix(reply, " 1. Pizza Place\n 2. NYC Deli") {
+		t.Errorf("index = %q, want the options title-cased", reply)
+	}
+	tp.run("room", "alice", "!poll start")
+	if reply := tp.run("room", "bob", "!poll export"); !strings.Contains(reply, "1. pizza place 0 votes") {
+		t.Errorf("export = %q, want the options as typed", reply)
+	}
+}
+
+func TestAnnounce(t *testing.T) {
+	tp := newTestPoller(t)
+
+	if reply := tp.run("room", "alice", "!poll announce A poll is coming at 2pm  about   lunch"); reply != "Upcoming poll: A poll is coming at 2pm  about   lunch" {
+		t.Errorf("announce reply = %q", reply)
+	}
+	if msgs := tp.broker.roomMessages("room"); len(msgs) != 1 {
+		t.Errorf("room messages = %q, want the announcement posted to the room", msgs)
+	}
+	if tp.hasPoll("room") {
+		t.Error("announce created a poll")
+	}
+}
+
+func BenchmarkVote(b *testing.B) {
+	tp := newTestPoller(b)
+	tp.prefs["room/vote.rate"] = "0"
+	tp.start("room", "", "Pizza", "Tacos", "Sushi")
+	users := make([]string, b.N)
+	for k := range users {
+		users[k] = fmt.Sprintf("user%d", k)
+	}
+
+	b.ResetTimer()
+	for k, user := range users {
+		if err := tp.Vote("room", user, k%3+1); err != nil {
+			b.Fatalf("Vote failed: %v", err)
+		}
+	}
+}
+
+// BenchmarkReadsWhileVoting runs show and index in parallel, along with a
+// vote every tenth call. The reads share the read lock, so they only wait on
+// the votes, not on each other; compare -cpu=1 and -cpu=4.
+func BenchmarkReadsWhileVoting(b *testing.B) {
+	tp := newTestPoller(b)
+	tp.prefs["room/vote.rate"] = "0"
+	tp.start("room", "", "Pizza", "Tacos", "Sushi")
+	var calls int64
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			n := atomic.AddInt64(&calls, 1)
+			switch {
+			case n%10 == 0:
+				if err := tp.Vote("room", fmt.Sprintf("user%d", n), int(n/10%3)+1); err != nil {
+					b.Errorf("Vote failed: %v", err)
+				}
+			case n%2 == 0:
+				tp.pollShow("room", "reader", showOptions{})
+			default:
+				tp.pollIndex("room")
+			}
+		}
+	})
+}
+
+func TestLockOptions(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.run("room", "alice", "!poll new Lunch?")
+
+	if reply := tp.run("room", "bob", "!poll lockoptions"); reply != "Only the creator of the poll or a poll admin can lock or unlock options." {
+		t.Errorf("lockoptions of a user reply = %q", reply)
+	}
+	tp.run("room", "alice", "!poll lockoptions")
+	if reply := tp.run("room", "bob", "!poll option Pizza"); reply != "Options are locked, only the creator of the poll can add options." {
+		t.Errorf("option while locked reply = %q", reply)
+	}
+	if reply := tp.run("room", "alice", "!poll option Pizza"); reply != "Added as option 1: Pizza" {
+		t.Errorf("option of the creator while locked reply = %q", reply)
+	}
+	tp.run("room", "alice", "!poll unlockoptions")
+	if reply := tp.run("room", "bob", "!poll option Tacos"); reply != "Added as option 2: Tacos" {
+		t.Errorf("option once unlocked reply = %q", reply)
+	}
+}
+
+func TestOptionsLockedPref(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.prefs["room/options.locked"] = "true"
+	tp.run("room", "alice", "!poll new Lunch?")
+	if reply := tp.run("room", "bob", "!poll option Pizza"); reply != "Options are locked, only the creator of the poll can add options." {
+		t.Errorf("option reply = %q", reply)
+	}
+}
+
+func TestIndexWithSortedDisplay(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.prefs["room/result.limit"] = "2"
+	tp.start("room", "", "Pizza", "Tacos", "Sushi")
+	tp.castVotes("room", 3, 3, 2)
+
+	if reply := tp.run("room", "bob", "!poll show"); !strings.Contains(reply, " 3. Sushi (2 votes)\n 2. Tacos (1 votes)") {
+		t.Fatalf("show = %q, want the options sorted by votes", reply)
+	}
+	want := "Options of Lunch?, vote with !poll vote <index>:\n 1. Pizza\n 2. Tacos\n 3. Sushi"
+	if reply := tp.run("room", "bob", "!poll index"); reply != want {
+		t.Errorf("index = %q, want %q", reply, want)
+	}
+}
+
+func TestRegisterTwice(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.Register("poll", "^!poll")
+	t.Cleanup(tp.Unregister)
+	plugin := tp.plugin
+	if plugin == nil {
+		t.Fatal("the plugin is not registered")
+	}
+
+	tp.Register("poll", "^!poll")
+	if tp.plugin != plugin {
+		t.Error("registering twice registered the plugin again")
+	}
+
+	tp.Unregister()
+	if tp.plugin != nil {
+		t.Error("the plugin is still registered after Unregister")
+	}
+	tp.Unregister()
+	tp.Register("poll", "^!poll")
+	if tp.plugin == nil || tp.plugin == plugin {
+		t.Error("the plugin could not be registered again after Unregister")
+	}
+}
+
+func TestConcurrentRevotes(t *testing.T) {
+	const revotes = 200
+	tp := newTestPoller(t)
+	logs := newLogRecorder(tp)
+	tp.prefs["room/vote.cooldown"] = "0s"
+	tp.start("room", "-lockvotes=1h", "Pizza", "Tacos", "Sushi")
+
+	var wg sync.WaitGroup
+	for k := 0; k < revotes; k++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tp.Vote("room", "bob", k%3+1)
+		}()
+	}
+	wg.Wait()
+
+	total := 0
+	for _, votes := range tp.votes("room") {
+		total += votes
+	}
+	if total != 1 {
+		t.Errorf("total after %d revotes = %d, want 1", revotes, total)
+	}
+	if recorded := len(logs.records(t, "vote recorded")); recorded < 2 {
+		t.Errorf("%d votes recorded, want the revotes to go through", recorded)
+	}
+	tp.mutex.RLock()
+	poll := tp.polls["room"]
+	if len(poll.Ballots) != 1 || len(poll.HasVoted) != 1 {
+		t.Errorf("ballots = %v, voters = %v, want a single one", poll.Ballots, poll.HasVoted)
+	}
+	tp.mutex.RUnlock()
+	for _, msg := range []string{"tally does not match the ballots", "ballots do not match the voters"} {
+		if got := logs.records(t, msg); len(got) > 0 {
+			t.Errorf("%s: %v", msg, got)
+		}
+	}
+}
@@ -0,0 +1,301 @@
+package poll
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/netflix/hal-9001/hal"
+)
+
+// fakeClock is a Clock whose time only moves with Advance, which calls the
+// functions scheduled until then in the order they are due.
+type fakeClock struct {
+	mutex  sync.Mutex
+	now    time.Time
+	timers []*fakeTimer
+}
+
+type fakeTimer struct {
+	clock *fakeClock
+	at    time.Time
+	f     func()
+	done  bool
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) AfterFunc(d time.Duration, f func()) Timer {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	t := &fakeTimer{clock: c, at: c.now.Add(d), f: f}
+	c.timers = append(c.timers, t)
+	return t
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mutex.Lock()
+	defer t.clock.mutex.Unlock()
+
+	pending := !t.done
+	t.done = true
+	return pending
+}
+
+// Advance moves the clock d forward, calling the functions due meanwhile.
+// Advance(0) calls those scheduled to run right away.
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mutex.Lock()
+	end := c.now.Add(d)
+	c.mutex.Unlock()
+
+	for {
+		c.mutex.Lock()
+		var next *fakeTimer
+		for _, t := range c.timers {
+			if !t.done && !t.at.After(end) && (next == nil || t.at.Before(next.at)) {
+				next = t
+			}
+		}
+		if next == nil {
+			c.now = end
+			c.mutex.Unlock()
+			return
+		}
+		if next.at.After(c.now) {
+			c.now = next.at
+		}
+		next.done = true
+		c.mutex.Unlock()
+
+		next.f()
+	}
+}
+
+// sent is a message sent by the fakeBroker, to a room, to a user or to a
+// user in a room only.
+type sent struct {
+	RoomId, UserId string
+	DM, Ephemeral  bool
+	Body           string
+}
+
+// fakeBroker records the messages sent to the rooms and to the users, in
+// the order they were sent.
+type fakeBroker struct {
+	mutex sync.Mutex
+	sent  []sent
+}
+
+func (b *fakeBroker) Name() string { return "fake" }
+
+func (b *fakeBroker) Send(evt hal.Evt) int {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.sent = append(b.sent, sent{RoomId: evt.RoomId, UserId: evt.UserId, Body: evt.Body})
+	return 0
+}
+
+func (b *fakeBroker) SendTable(evt hal.Evt, hdr []string, rows [][]string) int { return 0 }
+
+func (b *fakeBroker) SendDM(evt hal.Evt) int {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.sent = append(b.sent, sent{RoomId: evt.RoomId, UserId: evt.UserId, DM: true, Body: evt.Body})
+	return 0
+}
+
+func (b *fakeBroker) SetTopic(roomId, topic string) error    { return nil }
+func (b *fakeBroker) GetTopic(roomId string) (string, error) { return "", nil }
+func (b *fakeBroker) Leave(roomId string) error              { return nil }
+func (b *fakeBroker) Stream(out chan *hal.Evt)               {}
+func (b *fakeBroker) RoomIdToName(id string) string          { return id }
+func (b *fakeBroker) RoomNameToId(name string) string        { return name }
+func (b *fakeBroker) UserIdToName(id string) string          { return id }
+func (b *fakeBroker) UserNameToId(name string) string        { return name }
+func (b *fakeBroker) LooksLikeRoomId(room string) bool       { return true }
+func (b *fakeBroker) LooksLikeUserId(user string) bool       { return true }
+
+// memberBroker is a fakeBroker listing the members of the rooms.
+type memberBroker struct {
+	*fakeBroker
+	members map[string][]string
+}
+
+func (b memberBroker) RoomMembers(roomId string) ([]string, error) {
+	return b.members[roomId], nil
+}
+
+// directoryBroker is a fakeBroker looking up the ids of the users by name.
+// Ids start with U.
+type directoryBroker struct {
+	*fakeBroker
+	ids map[string]string
+}
+
+func (b directoryBroker) UserNameToId(name string) string { return b.ids[name] }
+func (b directoryBroker) LooksLikeUserId(user string) bool {
+	return strings.HasPrefix(user, "U")
+}
+
+// count returns the number of messages sent so far.
+func (b *fakeBroker) count() int {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return len(b.sent)
+}
+
+// since returns the messages sent after the first n.
+func (b *fakeBroker) since(n int) []sent {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return append([]sent(nil), b.sent[n:]...)
+}
+
+// roomMessages returns the bodies of the messages sent to the room, leaving
+// out the private ones.
+func (b *fakeBroker) roomMessages(roomId string) []string {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	var bodies []string
+	for _, m := range b.sent {
+		if m.RoomId == roomId && !m.DM {
+			bodies = append(bodies, m.Body)
+		}
+	}
+	return bodies
+}
+
+// testPoller is a Poller running on a fake clock, a fake broker and prefs
+// kept in memory.
+type testPoller struct {
+	*Poller
+	t      testing.TB
+	clock  *fakeClock
+	broker *fakeBroker
+	via    hal.Broker        // the broker of the events, broker unless set
+	prefs  map[string]string // keyed by room and key
+	events int
+}
+
+func newTestPoller(t testing.TB) *testPoller {
+	tp := &testPoller{
+		Poller: NewPoller(),
+		t:      t,
+		clock:  &fakeClock{now: time.Date(2024, 3, 4, 10, 0, 0, 0, time.UTC)},
+		broker: &fakeBroker{},
+		prefs:  make(map[string]string),
+	}
+	tp.SetLogger(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	SetClock(tp.clock)
+	oldGet, oldSet := getPref, setPref
+	var prefsMutex sync.Mutex
+	getPref = func(plugin, roomId, key, def string) string {
+		prefsMutex.Lock()
+		defer prefsMutex.Unlock()
+		if value, ok := tp.prefs[roomId+"/"+key]; ok {
+			return value
+		}
+		return def
+	}
+	setPref = func(plugin, roomId, key, value string) error {
+		prefsMutex.Lock()
+		defer prefsMutex.Unlock()
+		tp.prefs[roomId+"/"+key] = value
+		return nil
+	}
+	t.Cleanup(func() {
+		SetClock(nil)
+		getPref, setPref = oldGet, oldSet
+	})
+	return tp
+}
+
+// run sends the command of the user to the room and returns the replies,
+// in the room or in private, one per line.
+func (tp *testPoller) run(roomId, userId, body string) string {
+	tp.events++
+	n := tp.broker.count()
+	tp.poll(hal.Evt{
+		ID:     fmt.Sprint(tp.events),
+		Body:   body,
+		Room:   roomId,
+		RoomId: roomId,
+		User:   userId,
+		UserId: userId,
+		Time:   tp.clock.Now(),
+		Broker: tp.eventBroker(),
+	})
+	var replies []string
+	for _, m := range tp.broker.since(n) {
+		replies = append(replies, m.Body)
+	}
+	return strings.Join(replies, "\n")
+}
+
+// eventBroker returns the broker the events come from.
+func (tp *testPoller) eventBroker() hal.Broker {
+	if tp.via != nil {
+		return tp.via
+	}
+	return tp.broker
+}
+
+// start creates and starts a poll in the room with the flags and options.
+func (tp *testPoller) start(roomId, flags string, options ...string) {
+	tp.t.Helper()
+	tp.run(roomId, "alice", strings.TrimSpace("!poll new "+flags+" Lunch?"))
+	for _, o := range options {
+		tp.run(roomId, "alice", "!poll option "+o)
+	}
+	if reply := tp.run(roomId, "alice", "!poll start"); !strings.HasPrefix(reply, "Poll:") {
+		tp.t.Fatalf("starting the poll failed: %s", reply)
+	}
+}
+
+// castVotes votes for the options at indices in the poll of the room, as
+// the users voter1, voter2 and so on.
+func (tp *testPoller) castVotes(roomId string, indices ...int) {
+	tp.t.Helper()
+	for k, index := range indices {
+		if err := tp.Vote(roomId, fmt.Sprintf("voter%d", k+1), index); err != nil {
+			tp.t.Fatalf("Vote of voter%d for %d failed: %v", k+1, index, err)
+		}
+	}
+}
+
+// hasPoll reports whether the room has a poll.
+func (tp *testPoller) hasPoll(roomId string) bool {
+	tp.mutex.RLock()
+	defer tp.mutex.RUnlock()
+
+	_, ok := tp.polls[roomId]
+	return ok
+}
+
+// votes returns the votes of each option of the poll of the room.
+func (tp *testPoller) votes(roomId string) []int {
+	tp.mutex.RLock()
+	defer tp.mutex.RUnlock()
+
+	poll, ok := tp.polls[roomId]
+	if !ok {
+		return nil
+	}
+	votes := make([]int, len(poll.Options))
+	for k, o := range poll.Options {
+		votes[k] = o.Votes
+	}
+	return votes
+}
@@ -0,0 +1,89 @@
+package poll
+
+import (
+	"log/slog"
+	"sync"
+
+	"github.com/netflix/hal-9001/hal"
+)
+
+// Poller is an instance of the poll plugin. Each instance keeps its own
+// polls, so several poll plugins, e.g. "poll" and "standup-vote", can be
+// registered side by side. A zero Poller has no polls and creates its map on
+// the first poll, though it doesn't drop events delivered twice.
+type Poller struct {
+	name string
+
+	// mutex guards polls, closedPolls, pending, federations and outbox.
+	// Read-only commands share the read lock, so they don't wait on each
+	// other.
+	mutex       sync.RWMutex
+	polls       map[string]*pollEntry
+	closedPolls []closedPoll
+	pending     map[string]pendingAction // keyed by room and user id
+	federations map[string]string        // the room of the poll of each federation
+	outbox      []func()                 // replies sent by unlock
+
+	// seenMutex guards the tallies each user last looked at, which show
+	// records while holding the read lock alone.
+	seenMutex sync.Mutex
+
+	// pinMutex guards pins, the broker calls pinning and unpinning poll
+	// messages queued for each room.
+	pinMutex sync.Mutex
+	pins     map[string][]func()
+
+	// membersMutex guards members, the members of the rooms as last listed
+	// by the broker. It may be taken with the mutex held.
+	membersMutex sync.Mutex
+	members      map[string]memberList
+
+	// delegatesMutex serializes the changes to the delegates pref, which
+	// are made without holding the mutex.
+	delegatesMutex sync.Mutex
+
+	recentEvents *eventLRU
+	logger       *slog.Logger
+
+	// registerMutex guards plugin, the registration of the instance.
+	registerMutex sync.Mutex
+	plugin        *hal.Plugin
+}
+
+// NewPoller creates a poll plugin instance. Its polls are kept apart from
+// those of any other instance.
+func NewPoller() *Poller {
+	return &Poller{
+		name:         "poll",
+		polls:        make(map[string]*pollEntry),
+		recentEvents: newEventLRU(recentEventsSize),
+	}
+}
+
+// later queues f, usually a reply announcing what a vote or the end of a poll
+// caused, to be called once the mutex is released. It must be called with the
+// mutex held.
+func (pl *Poller) later(f func()) {
+	pl.outbox = append(pl.outbox, f)
+}
+
+// unlock releases the mutex and then calls the functions queued by later
+// while it was held, in the order they were queued.
+func (pl *Poller) unlock() {
+	outbox := pl.outbox
+	pl.outbox = nil
+	pl.mutex.Unlock()
+
+	for _, f := range outbox {
+		f()
+	}
+}
+
+// defaultPoller is the instance behind Register and the package-level
+// functions.
+var defaultPoller = NewPoller()
+
+// pref looks up a room-level preference of the instance.
+func (pl *Poller) pref(roomId, key, def string) string {
+	return getPref(pl.name, roomId, key, def)
+}
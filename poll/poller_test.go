@@ -0,0 +1,51 @@
+package poll
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestPollersIsolated(t *testing.T) {
+	polls := newTestPoller(t)
+	standup := newTestPoller(t)
+	standup.broker = polls.broker
+	polls.start("room", "", "Pizza", "Tacos")
+
+	if standup.hasPoll("room") {
+		t.Fatal("second instance has the poll of the first")
+	}
+	if err := standup.Vote("room", "bob", 1); !errors.Is(err, ErrNoPoll) {
+		t.Errorf("Vote on the second instance = %v, want ErrNoPoll", err)
+	}
+	standup.start("room", "", "Yesterday", "Today", "Blockers")
+	standup.castVotes("room", 3)
+	polls.castVotes("room", 2)
+
+	if got, want := polls.votes("room"), []int{0, 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("votes of the first instance = %v, want %v", got, want)
+	}
+	if got, want := standup.votes("room"), []int{0, 0, 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("votes of the second instance = %v, want %v", got, want)
+	}
+}
+
+func TestZeroPoller(t *testing.T) {
+	newTestPoller(t) // for the clock and the prefs
+	var pl Poller
+
+	if got := pl.pollShow("room", "bob", showOptions{}); got != "There is no poll." {
+		t.Errorf("show on a zero Poller = %q", got)
+	}
+	if err := pl.Vote("room", "bob", 1); !errors.Is(err, ErrNoPoll) {
+		t.Errorf("Vote on a zero Poller = %v, want ErrNoPoll", err)
+	}
+	if got := pl.pollNew("room", "alice", "Lunch?", newOptions{}); got == "" {
+		t.Error("new on a zero Poller replied nothing")
+	}
+	pl.mutex.RLock()
+	defer pl.mutex.RUnlock()
+	if poll, ok := pl.polls["room"]; !ok || poll.Title != "Lunch?" {
+		t.Errorf("polls of a zero Poller = %v, want the new poll", pl.polls)
+	}
+}
@@ -0,0 +1,50 @@
+package poll
+
+import (
+	"fmt"
+	"strings"
+)
+
+func (pl *Poller) pollAnswer(roomId, userId string, index int) string {
+	pl.mutex.Lock()
+	defer pl.unlock()
+
+	poll, ok := pl.polls[roomId]
+	if !ok {
+		return "There is no poll."
+	}
+	if !poll.Quiz {
+		return "The poll is not a quiz. Use !poll new -quiz <title> to create one."
+	}
+	if !pl.canManage(roomId, userId, poll) {
+		return "Only the creator of the poll or a poll admin can set the answer."
+	}
+	if index <= 0 || index > len(poll.Options) {
+		return fmt.Sprintf("Please choose a number between 1 to %d", len(poll.Options))
+	}
+
+	poll.Answer = index
+	return fmt.Sprintf("The correct answer is set to %s, it will be revealed at the end.", poll.Options[index-1].Text)
+}
+
+// quizResults reveals the correct answer of a quiz and who got it right.
+func (p pollEntry) quizResults() string {
+	if p.Answer == 0 {
+		return "No correct answer was set."
+	}
+	var correct []string
+	for _, b := range p.Ballots {
+		if b.Option == p.Answer-1 {
+			voter := b.UserName
+			if voter == "" {
+				voter = b.UserId
+			}
+			correct = append(correct, voter)
+		}
+	}
+	results := fmt.Sprintf("Correct answer: %s\n%d of %d voters got it right", p.Options[p.Answer-1].Text, len(correct), len(p.Ballots))
+	if len(correct) > 0 {
+		results = fmt.Sprintf("%s: %s", results, strings.Join(correct, ", "))
+	}
+	return results
+}
@@ -0,0 +1,37 @@
+package poll
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestQuiz(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.start("room", "-quiz", "Pizza", "Tacos", "Sushi")
+
+	if reply := tp.run("room", "bob", "!poll answer 2"); reply != "Only the creator of the poll or a poll admin can set the answer." {
+		t.Errorf("answer of bob = %q, want it refused", reply)
+	}
+	tp.run("room", "alice", "!poll answer 2")
+	for _, v := range []struct{ user, index string }{{"bob", "2"}, {"carol", "1"}, {"dave", "2"}} {
+		tp.run("room", v.user, "!poll vote "+v.index)
+	}
+	if reply := tp.run("room", "bob", "!poll show"); strings.Contains(reply, "Correct answer") {
+		t.Errorf("show = %q, want the answer hidden until the end", reply)
+	}
+
+	reply := tp.run("room", "alice", "!poll end")
+	if want := "Correct answer: Tacos\n2 of 3 voters got it right: bob, dave"; !strings.HasSuffix(reply, want) {
+		t.Errorf("end = %q, want it to end with %q", reply, want)
+	}
+}
+
+func TestQuizWithoutAnswer(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.start("room", "-quiz", "Pizza", "Tacos")
+	tp.run("room", "bob", "!poll vote 1")
+
+	if reply := tp.run("room", "alice", "!poll end"); !strings.HasSuffix(reply, "No correct answer was set.") {
+		t.Errorf("end = %q, want no correct answer", reply)
+	}
+}
@@ -0,0 +1,54 @@
+package poll
+
+import (
+	"fmt"
+)
+
+// quorum returns the number of voters the poll needs: that of -quorum=N, or
+// the share of the current members of the room of -quorum=P%. It returns
+// false if the poll has no quorum, or the members the share is taken of are
+// unknown. It must be called with the mutex held.
+func (pl *Poller) quorum(roomId string, poll *pollEntry) (int, bool) {
+	if poll.QuorumPercent > 0 {
+		_, members, ok := pl.turnout(roomId, poll)
+		if !ok {
+			return 0, false
+		}
+		return (members*poll.QuorumPercent + 99) / 100, true
+	}
+	return poll.Quorum, poll.Quorum > 0
+}
+
+// quorumLine tells whether the poll reached its quorum, e.g. "Quorum not
+// reached: 4 of 6 voters.", or returns an empty string if it has none. It
+// must be called with the mutex held.
+func (pl *Poller) quorumLine(roomId string, poll *pollEntry) string {
+	if poll.Quorum == 0 && poll.QuorumPercent == 0 {
+		return ""
+	}
+	needed, ok := pl.quorum(roomId, poll)
+	if !ok {
+		return "Quorum unknown, the members of the room cannot be listed."
+	}
+	if len(poll.HasVoted) < needed {
+		return fmt.Sprintf("Quorum not reached: %d of %d voters.", len(poll.HasVoted), needed)
+	}
+	return fmt.Sprintf("Quorum reached: %d of %d voters.", len(poll.HasVoted), needed)
+}
+
+// announceQuorum announces in the room that the poll created with -quorum
+// reached its quorum, the first time it does. It must be called with the
+// mutex held.
+func (pl *Poller) announceQuorum(roomId string, poll *pollEntry) {
+	if poll.QuorumAnnounced {
+		return
+	}
+	if needed, ok := pl.quorum(roomId, poll); !ok || len(poll.HasVoted) < needed {
+		return
+	}
+	poll.QuorumAnnounced = true
+	origin := poll.origin
+	pl.later(func() {
+		pl.reply(origin, "Quorum reached!")
+	})
+}
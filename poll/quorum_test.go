@@ -0,0 +1,95 @@
+package poll
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestQuorumAnnouncedOnce(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.start("room", "-quorum=2", "Pizza", "Tacos")
+
+	tp.run("room", "bob", "!poll vote 1")
+	if reply := tp.run("room", "carol", "!poll vote 2"); !strings.Contains(reply, "Quorum reached!") {
+		t.Errorf("replies to the vote reaching the quorum = %q, want the announcement", reply)
+	}
+	if reply := tp.run("room", "dave", "!poll vote 2"); strings.Contains(reply, "Quorum reached!") {
+		t.Errorf("replies to the next vote = %q, want no announcement", reply)
+	}
+	if reply := tp.run("room", "alice", "!poll end"); !strings.Contains(reply, "Quorum reached: 3 of 2 voters.") {
+		t.Errorf("results = %q, want the quorum line", reply)
+	}
+}
+
+func TestAutoCloseAfterAnnouncements(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.via = memberBroker{tp.broker, map[string][]string{"room": {"alice", "bob"}}}
+	tp.start("room", "-quorum=2 -autoclose", "Pizza", "Tacos")
+
+	tp.run("room", "alice", "!poll vote 1")
+	n := tp.broker.count()
+	tp.run("room", "bob", "!poll vote 1")
+
+	var bodies []string
+	for _, m := range tp.broker.since(n) {
+		if !m.DM {
+			bodies = append(bodies, strings.SplitN(m.Body, "\n", 2)[0])
+		}
+	}
+	// the confirmation of the vote goes to the room, the broker having no
+	// ephemeral messages, after the announcements
+	want := []string{"Quorum reached!", "Everybody voted! Poll finished, final results:", "Poll:"}
+	if !reflect.DeepEqual(bodies, want) {
+		t.Errorf("messages in the room = %q, want %q", bodies, want)
+	}
+	if tp.hasPoll("room") {
+		t.Error("the poll is still running once everybody voted")
+	}
+}
+
+func TestQuorumPercentOfMembers(t *testing.T) {
+	tp := newTestPoller(t)
+	members := make([]string, 10)
+	for k := range members {
+		members[k] = fmt.Sprintf("user%d", k)
+	}
+	tp.via = memberBroker{tp.broker, map[string][]string{"room": members}}
+	tp.start("room", "-quorum=60%", "Pizza", "Tacos")
+
+	for _, user := range members[:5] {
+		if reply := tp.run("room", user, "!poll vote 1"); strings.Contains(reply, "Quorum reached!") {
+			t.Fatalf("vote of %s = %q, want no announcement before 6 voters", user, reply)
+		}
+	}
+	if reply := tp.run("room", "alice", "!poll config"); !strings.Contains(reply, "quorum: 60% of the members") {
+		t.Errorf("config = %q", reply)
+	}
+	if reply := tp.run("room", members[5], "!poll vote 2"); !strings.Contains(reply, "Quorum reached!") {
+		t.Errorf("sixth vote = %q, want the announcement", reply)
+	}
+	if reply := tp.run("room", "alice", "!poll end"); !strings.Contains(reply, "Quorum reached: 6 of 6 voters.") {
+		t.Errorf("results = %q, want the quorum line", reply)
+	}
+}
+
+func TestQuorumPercentNotReached(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.via = memberBroker{tp.broker, map[string][]string{"room": {"alice", "bob", "carol", "dave"}}}
+	tp.start("room", "-quorum=50%", "Pizza", "Tacos")
+
+	tp.run("room", "bob", "!poll vote 1")
+	if reply := tp.run("room", "alice", "!poll end"); !strings.Contains(reply, "Quorum not reached: 1 of 2 voters.") {
+		t.Errorf("results = %q, want the quorum line", reply)
+	}
+}
+
+func TestQuorumPercentMembersUnknown(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.start("room", "-quorum=50%", "Pizza", "Tacos")
+	tp.castVotes("room", 1, 1)
+	if reply := tp.run("room", "alice", "!poll end"); !strings.Contains(reply, "Quorum unknown, the members of the room cannot be listed.") {
+		t.Errorf("results = %q, want the quorum unknown", reply)
+	}
+}
@@ -0,0 +1,28 @@
+package poll
+
+import "fmt"
+
+// draw picks one option at random with a probability proportional to its
+// votes, deterministically for the seed of the poll. It must be called with
+// the mutex held.
+func (p *pollEntry) draw() string {
+	total := 0
+	for _, o := range p.Options {
+		total += o.Votes
+	}
+	if total == 0 {
+		return "No winner drawn, nobody voted."
+	}
+
+	n := p.rand().Intn(total)
+	winner := 0
+	for k, o := range p.Options {
+		if n < o.Votes {
+			winner = k
+			break
+		}
+		n -= o.Votes
+	}
+	p.audit("", "drew the winner with seed %d, option %d", p.Seed, winner+1)
+	return fmt.Sprintf("Drawn winner (seed %d): %s", p.Seed, p.Options[winner].Text)
+}
@@ -0,0 +1,50 @@
+package poll
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestDrawDistribution(t *testing.T) {
+	const trials = 6000
+	p := pollEntry{Options: []pollOption{{Text: "Pizza", Votes: 1}, {Text: "Tacos", Votes: 2}, {Text: "Sushi", Votes: 3}}}
+	wins := make(map[string]int)
+	for seed := int64(1); seed <= trials; seed++ {
+		p.Seed, p.Audit = seed, nil
+		winner := strings.TrimPrefix(p.draw(), "Drawn winner (seed ")
+		_, winner, _ = strings.Cut(winner, "): ")
+		wins[winner]++
+	}
+	for _, o := range p.Options {
+		got, want := float64(wins[o.Text])/trials, float64(o.Votes)/6
+		if math.Abs(got-want) > 0.03 {
+			t.Errorf("%s drawn %.3f of the time, want about %.3f", o.Text, got, want)
+		}
+	}
+}
+
+func TestDrawDeterministic(t *testing.T) {
+	p := pollEntry{Seed: 42, Options: []pollOption{{Text: "Pizza", Votes: 5}, {Text: "Tacos", Votes: 5}}}
+	first := p.draw()
+	for k := 0; k < 10; k++ {
+		if got := p.draw(); got != first {
+			t.Fatalf("draw = %q, want %q again for the same seed", got, first)
+		}
+	}
+}
+
+func TestRaffle(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.start("room", "-raffle", "Pizza", "Tacos")
+	tp.castVotes("room", 2, 2)
+
+	if reply := tp.run("room", "alice", "!poll end"); !strings.Contains(reply, ": Tacos") || !strings.Contains(reply, "Drawn winner (seed ") {
+		t.Errorf("end = %q, want Tacos drawn", reply)
+	}
+
+	tp.start("room", "-raffle", "Pizza", "Tacos")
+	if reply := tp.run("room", "alice", "!poll end"); !strings.Contains(reply, "No winner drawn, nobody voted.") {
+		t.Errorf("end without votes = %q", reply)
+	}
+}
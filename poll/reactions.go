@@ -0,0 +1,158 @@
+package poll
+
+import "strings"
+
+// reactionIndices maps the number reactions of each broker type to option
+// indices. Slack names its emoji, Discord sends the emoji themselves.
+var reactionIndices = map[string]map[string]int{
+	"slack": {
+		"one": 1, "two": 2, "three": 3, "four": 4, "five": 5,
+		"six": 6, "seven": 7, "eight": 8, "nine": 9, "keycap_ten": 10,
+	},
+	"discord": {
+		"1️⃣": 1, "2️⃣": 2, "3️⃣": 3, "4️⃣": 4, "5️⃣": 5,
+		"6️⃣": 6, "7️⃣": 7, "8️⃣": 8, "9️⃣": 9, "\U0001f51f": 10,
+	},
+}
+
+// reactionIndex returns the option index a reaction stands for on the broker
+// type, or 0 if it is not a number reaction.
+func reactionIndex(brokerType, reaction string) int {
+	indices, ok := reactionIndices[strings.ToLower(brokerType)]
+	if !ok {
+		return 0
+	}
+	reaction = strings.Trim(reaction, ":")
+	if index, ok := indices[reaction]; ok {
+		return index
+	}
+	// keycaps are also sent without the variation selector
+	return indices[strings.Replace(reaction, "⃣", "️⃣", 1)]
+}
+
+// VoteByReaction casts the vote of the user for the option a number reaction
+// stands for, e.g. :three: on Slack or 3️⃣ on Discord. It returns
+// ErrInvalidIndex for other reactions, otherwise the same errors as Vote.
+func VoteByReaction(roomId, userId, brokerType, reaction string) error {
+	return defaultPoller.VoteByReaction(roomId, userId, brokerType, reaction)
+}
+
+// VoteByReaction casts the vote of the user reacting in the room's poll of
+// the instance, see VoteByReaction.
+func (pl *Poller) VoteByReaction(roomId, userId, brokerType, reaction string) error {
+	index := reactionIndex(brokerType, reaction)
+	if index == 0 {
+		return ErrInvalidIndex
+	}
+	return pl.Vote(roomId, userId, index)
+}
+
+// ReactionAdded counts a number reaction added by the user to the poll
+// message of a poll created with -reactions as a vote. It returns
+// ErrNotActive for reactions to the message of a poll that ended,
+// ErrNotPollMessage for reactions to other messages, ErrInvalidIndex for
+// other reactions, otherwise the same errors as Vote.
+func ReactionAdded(roomId, userId, messageId, brokerType, reaction string) error {
+	return defaultPoller.ReactionAdded(roomId, userId, messageId, brokerType, reaction)
+}
+
+// ReactionAdded counts a reaction to the poll message of the instance, see
+// ReactionAdded.
+func (pl *Poller) ReactionAdded(roomId, userId, messageId, brokerType, reaction string) error {
+	pl.fetchMembers(roomId)
+	pl.mutex.Lock()
+	defer pl.unlock()
+
+	index, err := pl.reactionVote(roomId, messageId, brokerType, reaction)
+	if err != nil {
+		return err
+	}
+	_, err = pl.castVote(roomId, userId, "", index)
+	return err
+}
+
+// ReactionRemoved withdraws the vote of the user for the option of a number
+// reaction removed from the poll message of a poll created with -reactions.
+// It returns the same errors as ReactionAdded, and nil when the user didn't
+// vote for the option.
+func ReactionRemoved(roomId, userId, messageId, brokerType, reaction string) error {
+	return defaultPoller.ReactionRemoved(roomId, userId, messageId, brokerType, reaction)
+}
+
+// ReactionRemoved withdraws a reaction to the poll message of the instance,
+// see ReactionRemoved.
+func (pl *Poller) ReactionRemoved(roomId, userId, messageId, brokerType, reaction string) error {
+	pl.mutex.Lock()
+	defer pl.unlock()
+
+	index, err := pl.reactionVote(roomId, messageId, brokerType, reaction)
+	if err != nil {
+		return err
+	}
+	poll := pl.polls[roomId]
+	poll.withdraw(userId, index)
+	pl.checkTally(roomId, poll)
+	return nil
+}
+
+// reactionVote returns the option index of a reaction to the poll message.
+// It must be called with the mutex held.
+func (pl *Poller) reactionVote(roomId, messageId, brokerType, reaction string) (int, error) {
+	if pl.endedMessage(roomId, messageId) {
+		return 0, ErrNotActive
+	}
+	poll, ok := pl.polls[roomId]
+	if !ok {
+		return 0, ErrNoPoll
+	}
+	if !poll.Reactions || poll.MessageId == "" || poll.MessageId != messageId {
+		return 0, ErrNotPollMessage
+	}
+	if !poll.IsActive {
+		return 0, ErrNotActive
+	}
+	index := reactionIndex(brokerType, reaction)
+	if index == 0 {
+		return 0, ErrInvalidIndex
+	}
+	return index, nil
+}
+
+// endedMessage reports whether the message is the poll message of a poll of
+// the room that ended, whose tally reactions no longer change. It must be
+// called with the mutex held.
+func (pl *Poller) endedMessage(roomId, messageId string) bool {
+	if messageId == "" {
+		return false
+	}
+	for _, c := range pl.closedPolls {
+		if c.RoomId == roomId && c.Poll.MessageId == messageId {
+			return true
+		}
+	}
+	return false
+}
+
+// withdraw takes back the vote of the user if it is for the option at index.
+// It must be called with the mutex held.
+func (p *pollEntry) withdraw(userId string, index int) {
+	for k, b := range p.Ballots {
+		if b.UserId != userId {
+			continue
+		}
+		if b.Option != index-1 {
+			return
+		}
+		p.Ballots = append(p.Ballots[:k], p.Ballots[k+1:]...)
+		p.Options[index-1].Votes--
+		for j, uId := range p.HasVoted {
+			if uId == userId {
+				p.HasVoted = append(p.HasVoted[:j], p.HasVoted[j+1:]...)
+				break
+			}
+		}
+		// reacting again right away is not a repeated command
+		delete(p.lastVote, userId)
+		return
+	}
+}
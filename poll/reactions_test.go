@@ -0,0 +1,112 @@
+package poll
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestReactionIndex(t *testing.T) {
+	for _, tt := range []struct {
+		broker, reaction string
+		want             int
+	}{
+		{"discord", "3️⃣", 3},
+		{"Discord", "3⃣", 3}, // without the variation selector
+		{"discord", "\U0001f51f", 10},
+		{"slack", ":three:", 3},
+		{"slack", "keycap_ten", 10},
+		{"slack", "3️⃣", 0},
+		{"discord", ":three:", 0},
+		{"irc", ":three:", 0},
+	} {
+		if got := reactionIndex(tt.broker, tt.reaction); got != tt.want {
+			t.Errorf("reactionIndex(%s, %q) = %d, want %d", tt.broker, tt.reaction, got, tt.want)
+		}
+	}
+}
+
+func TestVoteByReaction(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.start("room", "", "Pizza", "Tacos", "Sushi")
+
+	if err := tp.VoteByReaction("room", "bob", "discord", "3️⃣"); err != nil {
+		t.Fatalf("VoteByReaction failed: %v", err)
+	}
+	if err := tp.VoteByReaction("room", "carol", "discord", "👍"); !errors.Is(err, ErrInvalidIndex) {
+		t.Errorf("VoteByReaction of another emoji = %v, want ErrInvalidIndex", err)
+	}
+	if got, want := tp.votes("room"), []int{0, 0, 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("votes = %v, want %v", got, want)
+	}
+}
+func TestWithdrawReaction(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.start("room", "-reactions", "Pizza", "Tacos")
+	tp.mutex.Lock()
+	tp.polls["room"].MessageId = "m1"
+	tp.mutex.Unlock()
+
+	if err := tp.ReactionAdded("room", "bob", "m1", "slack", ":two:"); err != nil {
+		t.Fatalf("ReactionAdded failed: %v", err)
+	}
+	if err := tp.ReactionAdded("room", "bob", "m2", "slack", ":one:"); !errors.Is(err, ErrNotPollMessage) {
+		t.Errorf("reaction to another message = %v, want ErrNotPollMessage", err)
+	}
+	if err := tp.ReactionRemoved("room", "bob", "m1", "slack", ":one:"); err != nil {
+		t.Fatalf("ReactionRemoved of another option failed: %v", err)
+	}
+	if got, want := tp.votes("room"), []int{0, 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("votes after removing another reaction = %v, want %v", got, want)
+	}
+	if err := tp.ReactionRemoved("room", "bob", "m1", "slack", ":two:"); err != nil {
+		t.Fatalf("ReactionRemoved failed: %v", err)
+	}
+	if got, want := tp.votes("room"), []int{0, 0}; !reflect.DeepEqual(got, want) {
+		t.Errorf("votes after the withdrawal = %v, want %v", got, want)
+	}
+	// the withdrawal lets the user vote again right away
+	if err := tp.ReactionAdded("room", "bob", "m1", "slack", ":one:"); err != nil {
+		t.Errorf("ReactionAdded after the withdrawal failed: %v", err)
+	}
+}
+
+func TestReactionAfterEnd(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.start("room", "-reactions", "Pizza", "Tacos")
+	tp.mutex.Lock()
+	tp.polls["room"].MessageId = "m1"
+	tp.mutex.Unlock()
+	if err := tp.ReactionAdded("room", "bob", "m1", "slack", ":one:"); err != nil {
+		t.Fatalf("ReactionAdded failed: %v", err)
+	}
+	tp.run("room", "alice", "!poll end")
+
+	if err := tp.ReactionAdded("room", "carol", "m1", "slack", ":one:"); !errors.Is(err, ErrNotActive) {
+		t.Errorf("reaction after the end = %v, want ErrNotActive", err)
+	}
+	if err := tp.ReactionRemoved("room", "bob", "m1", "slack", ":one:"); !errors.Is(err, ErrNotActive) {
+		t.Errorf("reaction removed after the end = %v, want ErrNotActive", err)
+	}
+	c, _ := tp.lastClosed("room")
+	if got := []int{c.Poll.Options[0].Votes, c.Poll.Options[1].Votes}; !reflect.DeepEqual(got, []int{1, 0}) {
+		t.Errorf("final votes = %v, want them unchanged by the late reactions", got)
+	}
+}
+
+func TestReactionToTheMessageOfAnEndedPoll(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.start("room", "-reactions", "Pizza", "Tacos")
+	tp.mutex.Lock()
+	tp.polls["room"].MessageId = "m1"
+	tp.mutex.Unlock()
+	tp.run("room", "alice", "!poll end")
+	tp.start("room", "-reactions", "Soup", "Salad")
+
+	if err := tp.ReactionAdded("room", "carol", "m1", "slack", ":two:"); !errors.Is(err, ErrNotActive) {
+		t.Errorf("reaction to the message of the ended poll = %v, want ErrNotActive", err)
+	}
+	if got := tp.votes("room"); !reflect.DeepEqual(got, []int{0, 0}) {
+		t.Errorf("votes of the next poll = %v, want the late reaction ignored", got)
+	}
+}
@@ -0,0 +1,66 @@
+package poll
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Result styles accepted by !poll show -style.
+const (
+	styleDefault  = ""
+	styleFraction = "fraction"
+)
+
+// withHeader prefixes the rendered options with the title and description of
+// the poll.
+func (p pollEntry) withHeader(lines string) string {
+	if p.Description == "" {
+		return fmt.Sprintf("%s\n%s", p.Title, lines)
+	}
+	return fmt.Sprintf("%s\n%s\n%s", p.Title, p.Description, lines)
+}
+
+// fractionLines renders each option as its share of the total votes in the
+// language, e.g. "Pizza: 4/10 (40%)", marking the leading options.
+func (p pollEntry) fractionLines(lang string) string {
+	total, most := 0, 0
+	for _, o := range p.Options {
+		total += o.Votes
+		if o.Votes > most {
+			most = o.Votes
+		}
+	}
+
+	lines := make([]string, 0, len(p.Options)+1)
+	for k, o := range p.Options {
+		if total == 0 {
+			lines = append(lines, fmt.Sprintf(" %d. %s"+tr(lang, ": 0/0"), k+1, o.Text))
+			continue
+		}
+		line := fmt.Sprintf(" %d. %s"+tr(lang, ": %s/%s (%d%%)"), k+1, o.Text, formatCount(o.Votes), formatCount(total), o.Votes*100/total)
+		if o.Votes == most {
+			line += tr(lang, " ← leading")
+		}
+		lines = append(lines, line)
+	}
+	if total == 0 {
+		lines = append(lines, tr(lang, " Total: no votes yet"))
+	} else {
+		lines = append(lines, fmt.Sprintf(tr(lang, " Total: %s votes"), formatCount(total)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// formatCount renders a vote count with thousands separators, e.g. "12,345".
+func formatCount(n int) string {
+	digits := strconv.Itoa(n)
+	sign := ""
+	if n < 0 {
+		sign, digits = "-", digits[1:]
+	}
+	for i := len(digits) - 3; i > 0; i -= 3 {
+		digits = digits[:i] + "," + digits[i:]
+	}
+	return sign + digits
+}
@@ -0,0 +1,47 @@
+package poll
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFractionStyle(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.start("room", "", "Pizza", "Tacos", "Sushi")
+
+	want := "Poll:\nLunch?\n 1. Pizza: 0/0\n 2. Tacos: 0/0\n 3. Sushi: 0/0\n Total: no votes yet"
+	if reply := tp.run("room", "bob", "!poll show -style=fraction"); reply != want {
+		t.Errorf("show without votes = %q, want %q", reply, want)
+	}
+
+	tp.castVotes("room", 1, 1, 2, 1)
+	want = "Poll:\nLunch?\n 1. Pizza: 3/4 (75%) ← leading\n 2. Tacos: 1/4 (25%)\n 3. Sushi: 0/4 (0%)\n Total: 4 votes"
+	if reply := tp.run("room", "bob", "!poll show -style=fraction"); reply != want {
+		t.Errorf("show = %q, want %q", reply, want)
+	}
+}
+
+func TestFormatCount(t *testing.T) {
+	for n, want := range map[int]string{
+		0:       "0",
+		999:     "999",
+		1000:    "1,000",
+		12345:   "12,345",
+		1234567: "1,234,567",
+		-12345:  "-12,345",
+	} {
+		if got := formatCount(n); got != want {
+			t.Errorf("formatCount(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
+
+func TestLargeCountsRendered(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.start("room", "-budget=100000", "Pizza", "Tacos")
+	tp.run("room", "bob", "!poll allocate 1=12345")
+
+	if reply := tp.run("room", "bob", "!poll show"); !strings.Contains(reply, " 1. Pizza (12,345 points)") {
+		t.Errorf("show = %q, want 12,345 points", reply)
+	}
+}
@@ -0,0 +1,73 @@
+package poll
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// abstained reports whether the user may vote in a poll reopened for the
+// members who didn't vote, or the poll wasn't reopened.
+func (p pollEntry) abstained(userId string) bool {
+	if len(p.Abstainers) == 0 {
+		return true
+	}
+	for _, uId := range p.Abstainers {
+		if uId == userId {
+			return true
+		}
+	}
+	return false
+}
+
+// pollReopenAbstainers starts the last poll of the room that ended again,
+// letting only the current members of the room who didn't vote in it vote.
+func (pl *Poller) pollReopenAbstainers(roomId, userId string) string {
+	pl.mutex.Lock()
+	defer pl.unlock()
+
+	if poll, ok := pl.polls[roomId]; ok {
+		return fmt.Sprintf("The poll '%s' (%s) already exists.\nUse !poll remove to remove it.", poll.viewedBy("").Title, poll.Status())
+	}
+	last := -1
+	for k := len(pl.closedPolls) - 1; k >= 0; k-- {
+		if pl.closedPolls[k].RoomId == roomId {
+			last = k
+			break
+		}
+	}
+	if last < 0 {
+		return "There is no ended poll to reopen."
+	}
+	poll := pl.closedPolls[last].Poll
+	if !pl.canManage(roomId, userId, &poll) {
+		return "Only the creator of the poll or a poll admin can reopen it."
+	}
+	members, err := pl.roomMembers(roomId, poll.origin.Broker)
+	if errors.Is(err, errMembersUnknown) {
+		return "Cannot tell who didn't vote, the members of the room cannot be listed."
+	}
+	if err != nil {
+		return "Cannot tell who didn't vote, please try again later."
+	}
+	var abstainers []string
+	for _, member := range members {
+		if !poll.voted(member) {
+			abstainers = append(abstainers, member)
+		}
+	}
+	if len(abstainers) == 0 {
+		return "Everybody voted, there is nobody to reopen the poll for."
+	}
+
+	pl.closedPolls = append(pl.closedPolls[:last], pl.closedPolls[last+1:]...)
+	poll.Abstainers = abstainers
+	// a poll with a duration gets it anew from now on
+	poll.Deadline = time.Time{}
+	pl.polls[roomId] = &poll
+	pl.activate(roomId, &poll)
+	poll.audit(userId, "reopened the poll for %d members who didn't vote", len(abstainers))
+
+	return fmt.Sprintf("Poll '%s' reopened for the members who didn't vote: %s", poll.viewedBy("").Title, strings.Join(abstainers, ", "))
+}
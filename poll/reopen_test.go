@@ -0,0 +1,54 @@
+package poll
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestReopenAbstainers(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.via = memberBroker{tp.broker, map[string][]string{"room": {"alice", "bob", "carol"}}}
+	tp.start("room", "", "Pizza", "Tacos")
+	tp.run("room", "bob", "!poll vote 1")
+	tp.run("room", "alice", "!poll end")
+
+	if reply := tp.run("room", "bob", "!poll reopen-abstainers"); reply != "Only the creator of the poll or a poll admin can reopen it." {
+		t.Errorf("reopen-abstainers by a voter = %q", reply)
+	}
+	if reply := tp.run("room", "alice", "!poll reopen-abstainers"); reply != "Poll 'Lunch?' reopened for the members who didn't vote: alice, carol" {
+		t.Fatalf("reopen-abstainers reply = %q", reply)
+	}
+
+	if err := tp.Vote("room", "carol", 2); err != nil {
+		t.Errorf("vote of an abstainer failed: %v", err)
+	}
+	if err := tp.Vote("room", "bob", 2); !errors.Is(err, ErrNotAbstainer) {
+		t.Errorf("vote of a prior voter = %v, want ErrNotAbstainer", err)
+	}
+	if reply := tp.run("room", "bob", "!poll vote 2"); reply != errorMessage(ErrNotAbstainer) {
+		t.Errorf("!poll vote of a prior voter = %q", reply)
+	}
+	if err := tp.Vote("room", "dave", 2); !errors.Is(err, ErrNotAbstainer) {
+		t.Errorf("vote of a user who wasn't a member = %v, want ErrNotAbstainer", err)
+	}
+	if got := tp.votes("room"); !reflect.DeepEqual(got, []int{1, 1}) {
+		t.Errorf("votes = %v, want [1 1]", got)
+	}
+}
+
+func TestReopenAbstainersEverybodyVoted(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.via = memberBroker{tp.broker, map[string][]string{"room": {"alice", "bob"}}}
+	tp.start("room", "", "Pizza", "Tacos")
+	tp.run("room", "alice", "!poll vote 1")
+	tp.run("room", "bob", "!poll vote 2")
+	tp.run("room", "alice", "!poll end")
+
+	if reply := tp.run("room", "alice", "!poll reopen-abstainers"); reply != "Everybody voted, there is nobody to reopen the poll for." {
+		t.Errorf("reopen-abstainers reply = %q", reply)
+	}
+	if tp.hasPoll("room") {
+		t.Error("the poll was reopened")
+	}
+}
@@ -0,0 +1,53 @@
+package poll
+
+import "fmt"
+
+// pollRunoff ends the running poll and immediately starts a new one between
+// its two leading options. A room has a single poll, so the original poll
+// always ends. from is the room asking, which replies with the results.
+func (pl *Poller) pollRunoff(roomId, userId, from string) string {
+	pl.mutex.Lock()
+	defer pl.unlock()
+
+	poll, ok := pl.polls[roomId]
+	if !ok {
+		return "There is no poll."
+	}
+	if !poll.IsActive {
+		return "There is no active poll."
+	}
+	if !pl.canManage(roomId, userId, poll) {
+		return "Only the creator of the poll or a poll admin can start a runoff."
+	}
+	if len(poll.Options) < 2 {
+		return "A runoff needs at least two options."
+	}
+
+	top := poll.byVotes()[:2]
+	runoff := &pollEntry{
+		Title:       fmt.Sprintf("Runoff: %s", poll.Title),
+		Description: poll.Description,
+		Creator:     poll.Creator,
+		Allow:       poll.Allow,
+		Quiet:       poll.Quiet,
+		Open:        poll.Open,
+		Seed:        now().UnixNano(),
+		TieBreak:    poll.TieBreak,
+		Duration:    poll.Duration,
+		LockVotes:   poll.LockVotes,
+		origin:      poll.origin,
+	}
+	for _, k := range top {
+		o := poll.Options[k]
+		runoff.Options = append(runoff.Options, pollOption{Text: o.Text, Alias: o.Alias, URL: o.URL})
+	}
+	// the chained polls follow the runoff instead
+	runoff.Next, poll.Next = poll.Next, nil
+
+	results := pl.endPoll(roomId, poll, from)
+	runoff.audit(userId, "started the runoff with seed %d", runoff.Seed)
+	pl.addPoll(roomId, runoff)
+	pl.activate(roomId, runoff)
+
+	return fmt.Sprintf("%s\nRunoff:\n%s", results, runoff.Details())
+}
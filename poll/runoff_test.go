@@ -0,0 +1,46 @@
+package poll
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRunoff(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.start("room", "", "Pizza", "Tacos", "Sushi")
+	tp.castVotes("room", 3, 1, 3, 1, 3, 2)
+
+	reply := tp.run("room", "alice", "!poll runoff")
+	if !strings.Contains(reply, "Runoff:\n") {
+		t.Fatalf("runoff = %q, want the runoff started", reply)
+	}
+
+	tp.mutex.RLock()
+	poll := tp.polls["room"]
+	var options []string
+	for _, o := range poll.Options {
+		options = append(options, o.Text)
+	}
+	active, title := poll.IsActive, poll.Title
+	tp.mutex.RUnlock()
+
+	if want := []string{"Sushi", "Pizza"}; !reflect.DeepEqual(options, want) {
+		t.Errorf("options = %v, want the leaders %v", options, want)
+	}
+	if !active || title != "Runoff: Lunch?" {
+		t.Errorf("poll %q active = %v, want the runoff active", title, active)
+	}
+	if got := tp.votes("room"); !reflect.DeepEqual(got, []int{0, 0}) {
+		t.Errorf("votes = %v, want them reset", got)
+	}
+}
+
+func TestRunoffNotCreator(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.start("room", "", "Pizza", "Tacos", "Sushi")
+
+	if reply := tp.run("room", "bob", "!poll runoff"); reply != "Only the creator of the poll or a poll admin can start a runoff." {
+		t.Errorf("runoff of bob = %q, want it refused", reply)
+	}
+}
@@ -0,0 +1,38 @@
+package poll
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// pollSample lists n options of the poll picked at random, for reviewers to
+// curate when write-ins pile up. The pick is drawn from the seed of the
+// poll, so the same options come up until more are added, and the options
+// are listed in their order with their indices.
+func (pl *Poller) pollSample(roomId string, n int) string {
+	pl.mutex.RLock()
+	defer pl.mutex.RUnlock()
+
+	poll, ok := pl.polls[roomId]
+	if !ok {
+		return "There is no poll."
+	}
+	if n <= 0 {
+		return "Please sample at least one option."
+	}
+	if len(poll.Options) == 0 {
+		return "The poll has no options. Use !poll option <option> to add options."
+	}
+	if n > len(poll.Options) {
+		n = len(poll.Options)
+	}
+
+	picked := poll.rand().Perm(len(poll.Options))[:n]
+	sort.Ints(picked)
+	lines := make([]string, 0, n)
+	for _, k := range picked {
+		lines = append(lines, fmt.Sprintf(" %d. %s", k+1, poll.Options[k].label()))
+	}
+	return fmt.Sprintf("%d of the %d options of %s:\n%s", n, len(poll.Options), poll.viewedBy("").Title, strings.Join(lines, "\n"))
+}
@@ -0,0 +1,38 @@
+package poll
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSample(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.run("room", "alice", "!poll new -seed=7 Lunch?")
+	for _, o := range []string{"Pizza", "Tacos", "Sushi", "Curry", "Ramen", "Pho"} {
+		tp.run("room", "alice", "!poll option "+o)
+	}
+
+	want := "3 of the 6 options of Lunch?:\n 1. Pizza\n 3. Sushi\n 6. Pho"
+	for k := 0; k < 3; k++ {
+		if reply := tp.run("room", "bob", "!poll sample 3"); reply != want {
+			t.Fatalf("sample = %q, want %q under the seed", reply, want)
+		}
+	}
+
+	if reply := tp.run("room", "bob", "!poll sample 10"); !strings.HasPrefix(reply, "6 of the 6 options of Lunch?:") {
+		t.Errorf("sample of more options than the poll has = %q", reply)
+	}
+	if reply := tp.run("room", "bob", "!poll sample 0"); reply != "Please sample at least one option." {
+		t.Errorf("sample 0 = %q", reply)
+	}
+	tp.mutex.RLock()
+	defer tp.mutex.RUnlock()
+	var texts []string
+	for _, o := range tp.polls["room"].Options {
+		texts = append(texts, o.Text)
+	}
+	if want := []string{"Pizza", "Tacos", "Sushi", "Curry", "Ramen", "Pho"}; !reflect.DeepEqual(texts, want) {
+		t.Errorf("options after sampling = %q, want them in order", texts)
+	}
+}
@@ -0,0 +1,35 @@
+package poll
+
+// secretTitle is the title shown in place of the title of a poll created
+// with -secrettitle until it ends.
+const secretTitle = "Confidential poll"
+
+// hiddenResults is shown in place of the results hidden from a user, unless
+// the poll was created with -hiddenmsg.
+const hiddenResults = "Vote first to see results. Use !poll index to list the options."
+
+// viewedBy returns the poll as the user gets to see it. The title and
+// description of a poll created with -secrettitle are masked for everybody
+// but its creator. An empty userId stands for everybody in the room.
+func (p pollEntry) viewedBy(userId string) pollEntry {
+	if !p.SecretTitle || (userId != "" && userId == p.Creator) {
+		return p
+	}
+	p.Title, p.Description = secretTitle, ""
+	return p
+}
+
+// hidesResults reports whether the results of a running poll created with
+// -hideuntilvote are hidden from the user, who is yet to vote and isn't its
+// creator.
+func (p pollEntry) hidesResults(userId string) bool {
+	return p.IsActive && p.HideUntilVote && userId != p.Creator && !p.voted(userId)
+}
+
+// hiddenMessage returns the text shown in place of the hidden results.
+func (p pollEntry) hiddenMessage() string {
+	if p.HiddenMessage != "" {
+		return p.HiddenMessage
+	}
+	return hiddenResults
+}
@@ -0,0 +1,78 @@
+package poll
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSecretTitle(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.start("room", "-secrettitle", "Pizza", "Tacos")
+	tp.run("room", "bob", "!poll vote 1")
+
+	reply := tp.run("room", "bob", "!poll show")
+	if strings.Contains(reply, "Lunch?") || !strings.Contains(reply, secretTitle) {
+		t.Errorf("show to bob = %q, want the title masked", reply)
+	}
+	if !strings.Contains(reply, "Pizza") || !strings.Contains(reply, "Tacos") {
+		t.Errorf("show to bob = %q, want the options", reply)
+	}
+	if reply := tp.run("room", "alice", "!poll show"); !strings.Contains(reply, "Lunch?") {
+		t.Errorf("show to the creator = %q, want the title", reply)
+	}
+
+	if reply := tp.run("room", "alice", "!poll end"); !strings.Contains(reply, "Lunch?") {
+		t.Errorf("end = %q, want the title revealed", reply)
+	}
+}
+
+func TestSecretTitleMasked(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.run("room", "alice", "!poll new -secrettitle Surprise party for Dave?")
+
+	for _, reply := range []string{
+		tp.run("room", "alice", "!poll new Lunch?"),
+		tp.run("room", "alice", "!poll new -force -secrettitle Dinner?"),
+	} {
+		if strings.Contains(reply, "Surprise") {
+			t.Errorf("reply = %q, want the title masked", reply)
+		}
+	}
+}
+
+func TestHideUntilVote(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.start("room", "-hideuntilvote", "Pizza", "Tacos")
+	tp.run("room", "carol", "!poll vote 1")
+
+	if reply := tp.run("room", "bob", "!poll show"); reply != "Vote first to see results. Use !poll index to list the options." {
+		t.Errorf("show before voting = %q, want the results hidden", reply)
+	}
+	if reply := tp.run("room", "alice", "!poll show"); !strings.Contains(reply, "Pizza (1 votes)") {
+		t.Errorf("show to the creator = %q, want the results", reply)
+	}
+	tp.run("room", "bob", "!poll vote 2")
+	if reply := tp.run("room", "bob", "!poll show"); !strings.Contains(reply, "Tacos (1 votes)") {
+		t.Errorf("show after voting = %q, want the results", reply)
+	}
+	if reply := tp.run("room", "dave", "!poll index"); !strings.Contains(reply, "Pizza") || strings.Contains(reply, "votes") {
+		t.Errorf("index before voting = %q, want the options without the votes", reply)
+	}
+}
+
+func TestHiddenMessage(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.prefs["room/lang"] = "ja"
+	tp.start("room", `-hideuntilvote -hiddenmsg="Results revealed at close"`, "Pizza", "Tacos")
+	tp.run("room", "carol", "!poll vote 1")
+
+	for _, command := range []string{"!poll show", "!poll timeline", "!poll changes", "!poll export"} {
+		if reply := tp.run("room", "bob", command); reply != "Results revealed at close" {
+			t.Errorf("%s before voting = %q, want the hidden message", command, reply)
+		}
+	}
+	tp.run("room", "bob", "!poll vote 2")
+	if reply := tp.run("room", "bob", "!poll show"); strings.Contains(reply, "Results revealed at close") {
+		t.Errorf("show after voting = %q, want the results", reply)
+	}
+}
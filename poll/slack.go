@@ -0,0 +1,135 @@
+package poll
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/netflix/hal-9001/hal"
+)
+
+// blockSender is implemented by brokers that can send Slack Block Kit
+// messages. The body of the event is the text shown where blocks are not.
+type blockSender interface {
+	SendBlocks(evt hal.Evt, blocks []byte) error
+}
+
+// voteActionPrefix prefixes the action id of the vote buttons, followed by
+// the index of the option, e.g. "poll_vote_2".
+const voteActionPrefix = "poll_vote_"
+
+// Slack limits the elements of an actions block and the text of a button.
+const (
+	maxBlockButtons = 25
+	maxButtonText   = 75
+)
+
+type block struct {
+	Type     string        `json:"type"`
+	Text     *blockText    `json:"text,omitempty"`
+	Elements []blockButton `json:"elements,omitempty"`
+}
+
+type blockText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type blockButton struct {
+	Type     string    `json:"type"`
+	Text     blockText `json:"text"`
+	ActionId string    `json:"action_id"`
+	Value    string    `json:"value"`
+}
+
+// blocks renders the poll as Block Kit blocks, the text followed by a vote
+// button per option while the poll is active. The buttons show the votes of
+// their option, unless the poll hides the results until users vote, as the
+// card is for everybody in the room.
+func (p pollEntry) blocks(text string) ([]byte, error) {
+	blocks := []block{{
+		Type: "section",
+		Text: &blockText{Type: "mrkdwn", Text: text},
+	}}
+	if p.IsActive {
+		var actions *block
+		for k, o := range p.Options {
+			if k%maxBlockButtons == 0 {
+				blocks = append(blocks, block{Type: "actions"})
+				actions = &blocks[len(blocks)-1]
+			}
+			text := fmt.Sprintf("%d. %s (%s)", k+1, o.Text, formatCount(o.Votes))
+			if p.HideUntilVote {
+				text = fmt.Sprintf("%d. %s", k+1, o.Text)
+			}
+			text = truncate(text, maxButtonText)
+			actions.Elements = append(actions.Elements, blockButton{
+				Type:     "button",
+				Text:     blockText{Type: "plain_text", Text: text},
+				ActionId: voteActionPrefix + strconv.Itoa(k+1),
+				Value:    strconv.Itoa(k + 1),
+			})
+		}
+	}
+	return json.Marshal(blocks)
+}
+
+func (pl *Poller) pollBlocks(roomId, text string) ([]byte, error) {
+	pl.mutex.RLock()
+	defer pl.mutex.RUnlock()
+
+	poll, ok := pl.polls[roomId]
+	if !ok {
+		return nil, ErrNoPoll
+	}
+	return poll.blocks(text)
+}
+
+// replyShow replies with the poll as a Block Kit card on Slack, with show as
+// the text fallback, and with show alone elsewhere. A show too long for the
+// broker is truncated with the marker in the language of the show.
+func (pl *Poller) replyShow(evt hal.Evt, show, lang string) {
+	show = truncateMarked(show, pl.maxReplyLength(evt), tr(lang, truncatedMarker))
+	if sender, ok := evt.Broker.(blockSender); ok && brokerType(evt) == "slack" {
+		blocks, err := pl.pollBlocks(evt.RoomId, show)
+		if err == nil {
+			out := evt
+			out.Body = show
+			if err = sender.SendBlocks(out, blocks); err == nil {
+				return
+			}
+		}
+		if !errors.Is(err, ErrNoPoll) {
+			pl.log().Warn("sending the poll blocks failed", "room", evt.RoomId, "err", err)
+		}
+	}
+	pl.reply(evt, show)
+}
+
+// HandleBlockAction votes for the option of a vote button of a Block Kit
+// card, clicked by the user of the event. The broker calls it with the
+// action id of the button. It reports whether the action was a vote.
+func HandleBlockAction(evt hal.Evt, actionId string) bool {
+	return defaultPoller.HandleBlockAction(evt, actionId)
+}
+
+// HandleBlockAction votes for the option of a vote button of a card of the
+// instance, see HandleBlockAction.
+func (pl *Poller) HandleBlockAction(evt hal.Evt, actionId string) bool {
+	if !strings.HasPrefix(actionId, voteActionPrefix) {
+		return false
+	}
+	index, err := strconv.Atoi(strings.TrimPrefix(actionId, voteActionPrefix))
+	if err != nil {
+		return false
+	}
+	if msg := pl.refusal(evt.RoomId, evt.UserId, "vote"); msg != "" {
+		pl.replyVote(evt, msg)
+		return true
+	}
+	pl.fetchMembers(evt.RoomId)
+	pl.vote(evt, evt.RoomId, index)
+	return true
+}
@@ -0,0 +1,77 @@
+package poll
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/netflix/hal-9001/hal"
+)
+
+func TestBlocks(t *testing.T) {
+	p := pollEntry{
+		Title:    "Lunch?",
+		IsActive: true,
+		Options:  []pollOption{{Text: "Pizza", Votes: 2}, {Text: "Tacos"}},
+	}
+	data, err := p.blocks("Poll:\nLunch?")
+	if err != nil {
+		t.Fatalf("blocks failed: %v", err)
+	}
+	var got []block
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("blocks %s: %v", data, err)
+	}
+	want := []block{
+		{Type: "section", Text: &blockText{Type: "mrkdwn", Text: "Poll:\nLunch?"}},
+		{Type: "actions", Elements: []blockButton{
+			{Type: "button", Text: blockText{Type: "plain_text", Text: "1. Pizza (2)"}, ActionId: "poll_vote_1", Value: "1"},
+			{Type: "button", Text: blockText{Type: "plain_text", Text: "2. Tacos (0)"}, ActionId: "poll_vote_2", Value: "2"},
+		}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("blocks = %s, want %+v", data, want)
+	}
+}
+
+// slackBroker is a fakeBroker named slack recording the Block Kit messages
+// it sends.
+type slackBroker struct {
+	*fakeBroker
+	blocks *[]string
+}
+
+func (b slackBroker) Name() string { return "slack" }
+
+func (b slackBroker) SendBlocks(evt hal.Evt, blocks []byte) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	*b.blocks = append(*b.blocks, string(blocks))
+	return nil
+}
+
+func TestShowBlocksAndVoteButton(t *testing.T) {
+	tp := newTestPoller(t)
+	var sent []string
+	tp.via = slackBroker{tp.broker, &sent}
+	tp.start("room", "", "Pizza", "Tacos")
+
+	n := len(sent)
+	if reply := tp.run("room", "bob", "!poll show"); reply != "" {
+		t.Errorf("show on Slack replied %q, want the card only", reply)
+	}
+	if len(sent) != n+1 {
+		t.Fatalf("cards = %d, want the poll sent as a card", len(sent)-n)
+	}
+
+	evt := hal.Evt{RoomId: "room", UserId: "carol", User: "carol", Broker: tp.via}
+	if !tp.HandleBlockAction(evt, "poll_vote_2") {
+		t.Fatal("HandleBlockAction didn't handle the vote button")
+	}
+	if tp.HandleBlockAction(evt, "other_action") {
+		t.Error("HandleBlockAction handled another action")
+	}
+	if got, want := tp.votes("room"), []int{0, 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("votes = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,59 @@
+package poll
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// maxSnapshots is the number of snapshots retained per poll. Older
+// snapshots are dropped first.
+const maxSnapshots = 1000
+
+// snapshot is the tally of a poll at a point in time, taken periodically
+// for polls created with -snapshot.
+type snapshot struct {
+	Time  time.Time
+	Votes []int
+}
+
+// armSnapshots schedules the next snapshot of the poll, and the following
+// ones, until the poll ends. It must be called with the mutex held.
+func (pl *Poller) armSnapshots(roomId string, poll *pollEntry) {
+	if poll.SnapshotEvery <= 0 {
+		return
+	}
+	poll.snapshotter = afterFunc(poll.SnapshotEvery, func() {
+		pl.mutex.Lock()
+		defer pl.unlock()
+
+		if current, ok := pl.polls[roomId]; !ok || current != poll || !poll.IsActive {
+			return
+		}
+		votes := make([]int, len(poll.Options))
+		for k, o := range poll.Options {
+			votes[k] = o.Votes
+		}
+		poll.Snapshots = append(poll.Snapshots, snapshot{Time: now(), Votes: votes})
+		if len(poll.Snapshots) > maxSnapshots {
+			poll.Snapshots = poll.Snapshots[len(poll.Snapshots)-maxSnapshots:]
+		}
+		pl.armSnapshots(roomId, poll)
+	})
+}
+
+// snapshotLines renders the snapshots of the poll, e.g.
+// "2024-05-01T12:00:00Z Pizza 3, Tacos 2".
+func (p pollEntry) snapshotLines() []string {
+	lines := make([]string, 0, len(p.Snapshots))
+	for _, s := range p.Snapshots {
+		counts := make([]string, 0, len(s.Votes))
+		for k, votes := range s.Votes {
+			if k < len(p.Options) {
+				counts = append(counts, fmt.Sprintf("%s %s", p.Options[k].Text, formatCount(votes)))
+			}
+		}
+		lines = append(lines, fmt.Sprintf(" %s %s", s.Time.Format(time.RFC3339), strings.Join(counts, ", ")))
+	}
+	return lines
+}
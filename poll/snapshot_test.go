@@ -0,0 +1,42 @@
+package poll
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSnapshots(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.start("room", "-snapshot=1m", "Pizza", "Tacos")
+	tp.run("room", "bob", "!poll vote 1")
+	tp.clock.Advance(time.Minute)
+	tp.run("room", "carol", "!poll vote 2")
+	tp.run("room", "dave", "!poll vote 2")
+	tp.clock.Advance(time.Minute)
+
+	tp.mutex.RLock()
+	poll := tp.polls["room"]
+	want := []snapshot{
+		{Time: time.Date(2024, 3, 4, 10, 1, 0, 0, time.UTC), Votes: []int{1, 0}},
+		{Time: time.Date(2024, 3, 4, 10, 2, 0, 0, time.UTC), Votes: []int{1, 2}},
+	}
+	if !reflect.DeepEqual(poll.Snapshots, want) {
+		t.Errorf("snapshots = %+v, want %+v", poll.Snapshots, want)
+	}
+	tp.mutex.RUnlock()
+
+	timeline := tp.run("room", "bob", "!poll timeline")
+	if !strings.HasSuffix(timeline, "Snapshots:\n 2024-03-04T10:01:00Z Pizza 1, Tacos 0\n 2024-03-04T10:02:00Z Pizza 1, Tacos 2") {
+		t.Errorf("timeline = %q, want the snapshots", timeline)
+	}
+
+	tp.run("room", "alice", "!poll end")
+	tp.clock.Advance(time.Minute)
+	tp.mutex.RLock()
+	defer tp.mutex.RUnlock()
+	if len(poll.Snapshots) != 2 || poll.snapshotter != nil {
+		t.Errorf("snapshots after the end = %+v, want the snapshots stopped", poll.Snapshots)
+	}
+}
@@ -0,0 +1,138 @@
+package poll
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrNotFound is returned by a Storage when there is no value for a key.
+var ErrNotFound = errors.New("poll: not found")
+
+// Storage is the key-value store the plugin keeps durable data in.
+type Storage interface {
+	Put(key string, value []byte) error
+	Get(key string) ([]byte, error)
+	Delete(key string) error
+}
+
+// memStorage is the default Storage, keeping data in memory.
+type memStorage struct {
+	mutex sync.Mutex
+	data  map[string][]byte
+}
+
+// namedStorage is implemented by the storages naming their backend, e.g.
+// "redis", as listed by Capabilities.
+type namedStorage interface {
+	Name() string
+}
+
+// storageName returns the name of the backend of the storage, or "custom"
+// if it has none.
+func storageName(s Storage) string {
+	if n, ok := s.(namedStorage); ok && n.Name() != "" {
+		return n.Name()
+	}
+	return "custom"
+}
+
+func newMemStorage() *memStorage {
+	return &memStorage{data: make(map[string][]byte)}
+}
+
+func (s *memStorage) Name() string {
+	return "memory"
+}
+
+func (s *memStorage) Put(key string, value []byte) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.data[key] = append([]byte(nil), value...)
+	return nil
+}
+
+func (s *memStorage) Get(key string) ([]byte, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	value, ok := s.data[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return append([]byte(nil), value...), nil
+}
+
+func (s *memStorage) Delete(key string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.data, key)
+	return nil
+}
+
+var (
+	storage      Storage = newMemStorage()
+	storageMutex sync.Mutex
+)
+
+// SetStorage replaces the storage used by the plugin. A nil storage restores
+// the default in-memory one.
+func SetStorage(s Storage) {
+	storageMutex.Lock()
+	defer storageMutex.Unlock()
+
+	if s == nil {
+		s = newMemStorage()
+	}
+	storage = s
+}
+
+func currentStorage() Storage {
+	storageMutex.Lock()
+	defer storageMutex.Unlock()
+
+	return storage
+}
+
+// selftestPrefix is the key prefix of the data written by !poll selftest,
+// outside of the keys used for rooms.
+const selftestPrefix = "selftest/"
+
+// selftest checks that a value round-trips through the storage.
+func selftest() error {
+	s := currentStorage()
+	key := fmt.Sprintf("%s%d", selftestPrefix, time.Now().UnixNano())
+	value := []byte("hal-9001-poll selftest")
+
+	if err := s.Put(key, value); err != nil {
+		return fmt.Errorf("write failed: %v", err)
+	}
+	got, err := s.Get(key)
+	if err != nil {
+		return fmt.Errorf("read failed: %v", err)
+	}
+	if !bytes.Equal(got, value) {
+		return errors.New("read back a different value")
+	}
+	if err := s.Delete(key); err != nil {
+		return fmt.Errorf("delete failed: %v", err)
+	}
+	if _, err := s.Get(key); !errors.Is(err, ErrNotFound) {
+		return errors.New("value still present after delete")
+	}
+	return nil
+}
+
+func (pl *Poller) pollSelftest(roomId, userId string) string {
+	if !pl.isAdmin(roomId, userId) {
+		return "Only poll admins can run the selftest."
+	}
+	if err := selftest(); err != nil {
+		return fmt.Sprintf("Selftest failed: storage %v.", err)
+	}
+	return "Selftest OK."
+}
@@ -0,0 +1,37 @@
+package poll
+
+import (
+	"errors"
+	"testing"
+)
+
+// failingStorage is a Storage whose writes fail with err.
+type failingStorage struct {
+	*memStorage
+	err error
+}
+
+func (s failingStorage) Put(key string, value []byte) error { return s.err }
+
+func TestSelftest(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.prefs["room/admins"] = "root"
+	t.Cleanup(func() { SetStorage(nil) })
+
+	if reply := tp.run("room", "bob", "!poll selftest"); reply != "Only poll admins can run the selftest." {
+		t.Errorf("selftest of a user reply = %q", reply)
+	}
+	s := newMemStorage()
+	SetStorage(s)
+	if reply := tp.run("room", "root", "!poll selftest"); reply != "Selftest OK." {
+		t.Errorf("selftest reply = %q", reply)
+	}
+	if len(s.data) != 0 {
+		t.Errorf("selftest left %d keys behind", len(s.data))
+	}
+
+	SetStorage(failingStorage{newMemStorage(), errors.New("disk full")})
+	if reply := tp.run("room", "root", "!poll selftest"); reply != "Selftest failed: storage write failed: disk full." {
+		t.Errorf("selftest of a broken storage reply = %q", reply)
+	}
+}
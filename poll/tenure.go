@@ -0,0 +1,29 @@
+package poll
+
+import "time"
+
+// TenureBroker is implemented by the brokers able to tell how long a user
+// has been a member of a room, or has had an account. The minimum tenure of
+// polls created with -mintenure is not checked on other brokers.
+type TenureBroker interface {
+	// UserTenure returns how long the user has been around, or false if
+	// the broker does not know.
+	UserTenure(roomId, userId string) (time.Duration, bool)
+}
+
+// checkTenure refuses the vote of a user newer than the minimum tenure of
+// the poll, as reported by the broker the poll was created on.
+func (p pollEntry) checkTenure(roomId, userId string) error {
+	if p.MinTenure <= 0 {
+		return nil
+	}
+	b, ok := p.origin.Broker.(TenureBroker)
+	if !ok {
+		return nil
+	}
+	tenure, ok := b.UserTenure(roomId, userId)
+	if !ok || tenure >= p.MinTenure {
+		return nil
+	}
+	return tenureError{min: p.MinTenure}
+}
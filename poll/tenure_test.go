@@ -0,0 +1,45 @@
+package poll
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// tenureBroker is a fakeBroker knowing how long some users have been
+// around.
+type tenureBroker struct {
+	*fakeBroker
+	tenures map[string]time.Duration
+}
+
+func (b tenureBroker) UserTenure(roomId, userId string) (time.Duration, bool) {
+	tenure, ok := b.tenures[userId]
+	return tenure, ok
+}
+
+func TestMinTenure(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.via = tenureBroker{tp.broker, map[string]time.Duration{"bob": time.Hour, "carol": 30 * 24 * time.Hour}}
+	tp.start("room", "-mintenure=168h", "Pizza", "Tacos")
+
+	if reply := tp.run("room", "bob", "!poll vote 1"); !strings.HasPrefix(reply, "You must have been around for at least ") {
+		t.Errorf("vote of a new user reply = %q, want it refused", reply)
+	}
+	tp.run("room", "carol", "!poll vote 1")
+	tp.run("room", "dave", "!poll vote 2") // unknown to the broker
+	if got, want := tp.votes("room"), []int{1, 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("votes = %v, want %v", got, want)
+	}
+}
+
+func TestMinTenureUnsupported(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.start("room", "-mintenure=168h", "Pizza", "Tacos")
+
+	tp.run("room", "bob", "!poll vote 1")
+	if got, want := tp.votes("room"), []int{1, 0}; !reflect.DeepEqual(got, want) {
+		t.Errorf("votes = %v, want the check skipped %v", got, want)
+	}
+}
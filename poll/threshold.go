@@ -0,0 +1,75 @@
+package poll
+
+import (
+	"fmt"
+	"sync"
+)
+
+// ThresholdHook is called when an option of a poll reaches the number of
+// votes set with !poll threshold, e.g. to order the pizza once ten people
+// voted for it. It is called once per option, after the vote is recorded
+// and the plugin state is unlocked.
+type ThresholdHook func(roomId, title string, index int, option string)
+
+var (
+	thresholdHooks []ThresholdHook
+	hooksMutex     sync.RWMutex
+)
+
+// RegisterThresholdHook adds a hook called when an option reaches its
+// threshold.
+func RegisterThresholdHook(h ThresholdHook) {
+	hooksMutex.Lock()
+	defer hooksMutex.Unlock()
+
+	thresholdHooks = append(thresholdHooks, h)
+}
+
+// crossThresholds calls the hooks for the options of the poll that reached
+// their threshold and didn't before. It must be called with the mutex held.
+func (pl *Poller) crossThresholds(roomId string, poll *pollEntry) {
+	for k := range poll.Options {
+		o := &poll.Options[k]
+		if o.Threshold == 0 || o.ThresholdReached || o.Votes < o.Threshold {
+			continue
+		}
+		o.ThresholdReached = true
+		title, index, option := poll.Title, k+1, o.Text
+		pl.later(func() {
+			hooksMutex.RLock()
+			defer hooksMutex.RUnlock()
+
+			for _, h := range thresholdHooks {
+				h(roomId, title, index, option)
+			}
+		})
+	}
+}
+
+// pollThreshold sets the number of votes the option at index needs to call
+// the threshold hooks, or clears it if votes is 0.
+func (pl *Poller) pollThreshold(roomId, userId string, index, votes int) string {
+	pl.mutex.Lock()
+	defer pl.unlock()
+
+	poll, ok := pl.polls[roomId]
+	if !ok {
+		return "There is no poll."
+	}
+	if !pl.canManage(roomId, userId, poll) {
+		return "Only the creator of the poll or a poll admin can set thresholds."
+	}
+	if index <= 0 || index > len(poll.Options) {
+		return fmt.Sprintf("Please choose a number between 1 to %d", len(poll.Options))
+	}
+	if votes < 0 {
+		return "The threshold must be a positive number of votes, or 0 to clear it."
+	}
+
+	o := &poll.Options[index-1]
+	o.Threshold, o.ThresholdReached = votes, false
+	if votes == 0 {
+		return fmt.Sprintf("Threshold of %s cleared.", o.Text)
+	}
+	return fmt.Sprintf("%s reaches its threshold at %d votes.", o.Text, votes)
+}
@@ -0,0 +1,45 @@
+package poll
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestThresholdHook(t *testing.T) {
+	tp := newTestPoller(t)
+	type call struct {
+		Room, Title string
+		Index       int
+		Option      string
+		Sent        int // messages sent before the call
+	}
+	var calls []call
+	RegisterThresholdHook(func(roomId, title string, index int, option string) {
+		calls = append(calls, call{roomId, title, index, option, tp.broker.count()})
+	})
+	t.Cleanup(func() {
+		hooksMutex.Lock()
+		defer hooksMutex.Unlock()
+		thresholdHooks = nil
+	})
+	tp.start("room", "-quorum=3", "Pizza", "Tacos")
+	if reply := tp.run("room", "alice", "!poll threshold 2 3"); reply != "Tacos reaches its threshold at 3 votes." {
+		t.Fatalf("threshold reply = %q", reply)
+	}
+
+	tp.run("room", "bob", "!poll vote 2")
+	tp.run("room", "carol", "!poll vote 2")
+	if len(calls) != 0 {
+		t.Fatalf("hook called below the threshold: %+v", calls)
+	}
+	n := tp.broker.count()
+	tp.run("room", "dave", "!poll vote 2")
+	tp.run("room", "erin", "!poll vote 2")
+
+	// the quorum announced by the same vote is sent first, then the vote is
+	// confirmed once the hook returns
+	want := []call{{"room", "Lunch?", 2, "Tacos", n + 1}}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("hook calls = %+v, want %+v", calls, want)
+	}
+}
@@ -0,0 +1,59 @@
+package poll
+
+import (
+	"strconv"
+	"time"
+)
+
+// tokenBucket limits a rate of events, allowing bursts up to its capacity.
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// take refills the bucket at rate tokens per second up to burst tokens, and
+// takes a token if there is one.
+func (b *tokenBucket) take(now time.Time, rate float64, burst int) bool {
+	if b.last.IsZero() {
+		b.tokens = float64(burst)
+	} else if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens += elapsed * rate
+	}
+	if b.tokens > float64(burst) {
+		b.tokens = float64(burst)
+	}
+	b.last = now
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// voteRate returns the votes per second a poll accepts in the long run and
+// the burst of votes it accepts at once, as set by the room's vote.rate and
+// vote.burst prefs. A zero rate disables the limit.
+func (pl *Poller) voteRate(roomId string) (float64, int) {
+	rate, err := strconv.ParseFloat(pl.pref(roomId, "vote.rate", "10"), 64)
+	if err != nil || rate < 0 {
+		rate = 10
+	}
+	burst, err := strconv.Atoi(pl.pref(roomId, "vote.burst", "50"))
+	if err != nil || burst < 1 {
+		burst = 50
+	}
+	return rate, burst
+}
+
+// throttle takes a token from the vote bucket of the poll, returning
+// ErrThrottled when it is empty. It must be called with the mutex held.
+func (pl *Poller) throttle(roomId string, poll *pollEntry, now time.Time) error {
+	rate, burst := pl.voteRate(roomId)
+	if rate == 0 {
+		return nil
+	}
+	if !poll.votes.take(now, rate, burst) {
+		return ErrThrottled
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package poll
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestVoteThrottled(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.prefs["room/vote.rate"] = "1"
+	tp.prefs["room/vote.burst"] = "2"
+	tp.start("room", "", "Pizza", "Tacos")
+
+	for _, user := range []string{"bob", "carol"} {
+		if err := tp.Vote("room", user, 1); err != nil {
+			t.Fatalf("Vote of %s failed: %v", user, err)
+		}
+	}
+	if err := tp.Vote("room", "dave", 1); !errors.Is(err, ErrThrottled) {
+		t.Errorf("Vote beyond the burst = %v, want ErrThrottled", err)
+	}
+	if reply := tp.run("room", "dave", "!poll vote 1"); reply != "Too many votes right now, please retry." {
+		t.Errorf("vote beyond the burst reply = %q", reply)
+	}
+	tp.clock.Advance(time.Second)
+	if err := tp.Vote("room", "dave", 1); err != nil {
+		t.Errorf("Vote once refilled failed: %v", err)
+	}
+}
+
+func TestVotePacedNotThrottled(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.prefs["room/vote.rate"] = "1"
+	tp.prefs["room/vote.burst"] = "1"
+	tp.start("room", "", "Pizza", "Tacos")
+
+	for _, user := range []string{"bob", "carol", "dave", "eve"} {
+		if err := tp.Vote("room", user, 2); err != nil {
+			t.Errorf("Vote of %s a second after the last failed: %v", user, err)
+		}
+		tp.clock.Advance(time.Second)
+	}
+}
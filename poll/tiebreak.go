@@ -0,0 +1,52 @@
+package poll
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// Tie-break modes of !poll new -tiebreak.
+const (
+	tieBreakNone   = ""
+	tieBreakRandom = "random"
+)
+
+// rand returns a source of randomness seeded with the seed of the poll, so
+// that random decisions can be reproduced from the audit log.
+func (p pollEntry) rand() *rand.Rand {
+	return rand.New(rand.NewSource(p.Seed))
+}
+
+// leaders returns the indices of the options tied for the most votes, if
+// any option has votes.
+func (p pollEntry) leaders() []int {
+	most := 0
+	for _, o := range p.Options {
+		if o.Votes > most {
+			most = o.Votes
+		}
+	}
+	var leaders []int
+	if most == 0 {
+		return leaders
+	}
+	for k, o := range p.Options {
+		if o.Votes == most {
+			leaders = append(leaders, k)
+		}
+	}
+	return leaders
+}
+
+// breakTie picks one of the options tied for the most votes at random,
+// deterministically for the seed of the poll. It returns an empty string if
+// there is no tie. It must be called with the mutex held.
+func (p *pollEntry) breakTie() string {
+	leaders := p.leaders()
+	if p.TieBreak != tieBreakRandom || len(leaders) < 2 {
+		return ""
+	}
+	winner := leaders[p.rand().Intn(len(leaders))]
+	p.audit("", "broke the tie at random with seed %d, winner option %d", p.Seed, winner+1)
+	return fmt.Sprintf("Tie broken at random (seed %d): %s", p.Seed, p.Options[winner].Text)
+}
@@ -0,0 +1,41 @@
+package poll
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTieBreakSeeded(t *testing.T) {
+	var ties []string
+	for k := 0; k < 2; k++ {
+		tp := newTestPoller(t)
+		tp.start("room", "-tiebreak=random -seed=42", "Pizza", "Tacos", "Sushi", "Curry")
+		tp.castVotes("room", 1, 2, 3, 4)
+
+		tp.mutex.RLock()
+		created := tp.polls["room"].Audit[0].Action
+		tp.mutex.RUnlock()
+		if created != "created the poll with seed 42" {
+			t.Errorf("audit = %q, want the seed", created)
+		}
+
+		reply := tp.run("room", "alice", "!poll end")
+		i := strings.Index(reply, "Tie broken at random (seed 42): ")
+		if i < 0 {
+			t.Fatalf("results = %q, want the tie broken", reply)
+		}
+		ties = append(ties, reply[i:])
+	}
+	if ties[0] != ties[1] {
+		t.Errorf("ties broken with the same seed = %q, want the same winner", ties)
+	}
+}
+
+func TestTieBreakNoTie(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.start("room", "-tiebreak=random", "Pizza", "Tacos")
+	tp.castVotes("room", 1, 1, 2)
+	if reply := tp.run("room", "alice", "!poll end"); strings.Contains(reply, "Tie broken") {
+		t.Errorf("results = %q, want no tie break", reply)
+	}
+}
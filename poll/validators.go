@@ -0,0 +1,54 @@
+package poll
+
+import (
+	"fmt"
+	"sync"
+)
+
+// VoteValidator checks a vote for the option at index before it is
+// recorded. A non-nil error refuses the vote, with the error as the reply.
+// Validators run while the plugin state is locked and must not call back
+// into the plugin.
+type VoteValidator func(roomId, userId string, index int) error
+
+var (
+	voteValidators  []VoteValidator
+	validatorsMutex sync.RWMutex
+)
+
+// RegisterVoteValidator adds a validator checked on every vote.
+func RegisterVoteValidator(v VoteValidator) {
+	validatorsMutex.Lock()
+	defer validatorsMutex.Unlock()
+
+	voteValidators = append(voteValidators, v)
+}
+
+// validateVote runs the registered validators.
+func validateVote(roomId, userId string, index int) error {
+	validatorsMutex.RLock()
+	defer validatorsMutex.RUnlock()
+
+	for _, v := range voteValidators {
+		if err := v(roomId, userId, index); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ClosedHours returns a validator refusing votes from the hour from until the
+// hour to of the plugin's clock, e.g. ClosedHours(12, 13) for the lunch hour.
+func ClosedHours(from, to int) VoteValidator {
+	return func(roomId, userId string, index int) error {
+		hour := now().Hour()
+		closed := hour >= from && hour < to
+		if from > to {
+			closed = hour >= from || hour < to
+		}
+		if closed {
+			return fmt.Errorf("Voting is closed from %02d:00 to %02d:00.", from, to)
+		}
+		return nil
+	}
+}
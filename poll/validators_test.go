@@ -0,0 +1,60 @@
+package poll
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// registerVoteValidator registers the validator for the duration of the
+// test.
+func registerVoteValidator(t *testing.T, v VoteValidator) {
+	RegisterVoteValidator(v)
+	t.Cleanup(func() {
+		validatorsMutex.Lock()
+		defer validatorsMutex.Unlock()
+		voteValidators = nil
+	})
+}
+
+func TestVoteValidators(t *testing.T) {
+	tp := newTestPoller(t)
+	var checked []string
+	registerVoteValidator(t, func(roomId, userId string, index int) error {
+		checked = append(checked, userId)
+		return nil
+	})
+	registerVoteValidator(t, func(roomId, userId string, index int) error {
+		if userId == "mallory" {
+			return errors.New("You must be on call to vote.")
+		}
+		return nil
+	})
+	tp.start("room", "", "Pizza", "Tacos")
+
+	if reply := tp.run("room", "mallory", "!poll vote 1"); reply != "You must be on call to vote." {
+		t.Errorf("refused vote reply = %q", reply)
+	}
+	tp.run("room", "bob", "!poll vote 1")
+	if got, want := tp.votes("room"), []int{1, 0}; !reflect.DeepEqual(got, want) {
+		t.Errorf("votes = %v, want %v", got, want)
+	}
+	if want := []string{"mallory", "bob"}; !reflect.DeepEqual(checked, want) {
+		t.Errorf("validated votes of %q, want %q", checked, want)
+	}
+}
+
+func TestClosedHours(t *testing.T) {
+	tp := newTestPoller(t)
+	registerVoteValidator(t, ClosedHours(10, 11))
+	tp.start("room", "", "Pizza", "Tacos")
+
+	if reply := tp.run("room", "bob", "!poll vote 1"); reply != "Voting is closed from 10:00 to 11:00." {
+		t.Errorf("vote in the closed hours reply = %q", reply)
+	}
+	tp.clock.Advance(time.Hour)
+	if err := tp.Vote("room", "bob", 1); err != nil {
+		t.Errorf("Vote after the closed hours failed: %v", err)
+	}
+}
@@ -0,0 +1,68 @@
+package poll
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestVerbs(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.start("room", "-verbs=Approve,reject", "Yes", "No")
+
+	tp.run("room", "bob", "!poll approve")
+	tp.run("room", "carol", "!poll APPROVE")
+	tp.run("room", "dave", "!poll Reject")
+	tp.run("room", "eve", "!poll vote 2")
+	if got, want := tp.votes("room"), []int{2, 2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("votes = %v, want %v", got, want)
+	}
+}
+
+func TestVerbsParsed(t *testing.T) {
+	for _, tt := range []struct {
+		flag string
+		want []string
+		err  bool
+	}{
+		{"-verbs=Approve,REJECT", []string{"approve", "reject"}, false},
+		{"-verbs=approve,Approve", nil, true},
+		{"-verbs=Show,hide", nil, true},
+		{"-verbs=approve,2", nil, true},
+	} {
+		opts, _, err := parseNewOptions([]string{tt.flag, "Lunch?"})
+		if (err != nil) != tt.err {
+			t.Errorf("parseNewOptions(%s) error = %v, want an error %t", tt.flag, err, tt.err)
+		}
+		if err == nil && !reflect.DeepEqual(opts.Verbs, tt.want) {
+			t.Errorf("parseNewOptions(%s) verbs = %q, want %q", tt.flag, opts.Verbs, tt.want)
+		}
+	}
+}
+
+func TestVerbsCountChecked(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.run("room", "alice", "!poll new -verbs=approve,reject Lunch?")
+	for _, o := range []string{"Pizza", "Tacos", "Sushi"} {
+		tp.run("room", "alice", "!poll option "+o)
+	}
+
+	if reply := tp.run("room", "alice", "!poll start"); reply != "The poll has 2 verbs for 3 options, one verb per option is needed." {
+		t.Errorf("start reply = %q, want it refused", reply)
+	}
+	tp.run("room", "alice", "!poll unoption 3")
+	if reply := tp.run("room", "alice", "!poll start"); !strings.HasPrefix(reply, "Poll:") {
+		t.Errorf("start reply = %q, want the poll started", reply)
+	}
+}
+
+func TestVoteAliasesFoldCase(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.prefs["room/vote.aliases"] = "Aye"
+	tp.start("room", "", "Pizza", "Tacos")
+
+	tp.run("room", "bob", "!poll AYE 2")
+	if got, want := tp.votes("room"), []int{0, 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("votes = %v, want %v", got, want)
+	}
+}
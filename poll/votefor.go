@@ -0,0 +1,57 @@
+package poll
+
+import (
+	"errors"
+	"fmt"
+)
+
+// VoteFor casts a vote for the option at index on behalf of the target user,
+// e.g. for automation voting for users who answered elsewhere. The vote is
+// attributed to the target user and the actor is recorded in the audit log.
+// It returns the same errors as Vote.
+func VoteFor(roomId, actorId, targetUserId string, index int) error {
+	return defaultPoller.VoteFor(roomId, actorId, targetUserId, index)
+}
+
+// VoteFor casts a vote on behalf of the target user in the room's poll of
+// the instance, see VoteFor.
+func (pl *Poller) VoteFor(roomId, actorId, targetUserId string, index int) error {
+	pl.fetchMembers(roomId)
+	pl.mutex.Lock()
+	defer pl.unlock()
+
+	_, err := pl.voteFor(roomId, actorId, targetUserId, index)
+	return err
+}
+
+// voteFor is VoteFor, returning the poll voted in. It must be called with the
+// mutex held.
+func (pl *Poller) voteFor(roomId, actorId, targetUserId string, index int) (*pollEntry, error) {
+	poll, err := pl.castVote(roomId, targetUserId, "", index)
+	if err != nil {
+		return nil, err
+	}
+	poll.audit(actorId, "voted for option %d on behalf of %s", index, targetUserId)
+	return poll, nil
+}
+
+func (pl *Poller) pollVoteFor(roomId, actorId, targetUserId string, index int) string {
+	if !pl.isAdmin(roomId, actorId) {
+		return "Only poll admins can vote on behalf of other users."
+	}
+
+	pl.mutex.Lock()
+	defer pl.unlock()
+
+	poll, err := pl.voteFor(roomId, actorId, targetUserId, index)
+	if errors.Is(err, ErrAlreadyVoted) {
+		return fmt.Sprintf("%s has already voted.", targetUserId)
+	}
+	if errors.Is(err, ErrNotEligible) {
+		return fmt.Sprintf("%s is not eligible to vote in this poll.", targetUserId)
+	}
+	if err != nil {
+		return errorMessage(err)
+	}
+	return fmt.Sprintf("Vote recorded for %s on behalf of %s", poll.Options[index-1].Text, targetUserId)
+}
@@ -0,0 +1,47 @@
+package poll
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestVoteFor(t *testing.T) {
+	tp := newTestPoller(t)
+	tp.prefs["room/admins"] = "root"
+	tp.via = directoryBroker{tp.broker, map[string]string{"bob": "U2"}}
+	tp.start("room", "", "Pizza", "Tacos")
+
+	if reply := tp.run("room", "carol", "!poll votefor @bob 1"); reply != "Only poll admins can vote on behalf of other users." {
+		t.Errorf("votefor of a user reply = %q", reply)
+	}
+	if reply := tp.run("room", "root", "!poll votefor @bob 1"); reply != "Vote recorded for Pizza on behalf of U2" {
+		t.Errorf("votefor reply = %q", reply)
+	}
+	tp.clock.Advance(time.Minute)
+	if err := tp.Vote("room", "U2", 2); !errors.Is(err, ErrAlreadyVoted) {
+		t.Errorf("Vote of the user voted for = %v, want ErrAlreadyVoted", err)
+	}
+	if reply := tp.run("room", "root", "!poll votefor <@U2> 2"); reply != "U2 has already voted." {
+		t.Errorf("second votefor reply = %q", reply)
+	}
+	// handles the broker doesn't know are kept as they are
+	if reply := tp.run("room", "root", "!poll votefor @zed 2"); reply != "Vote recorded for Tacos on behalf of zed" {
+		t.Errorf("votefor of an unknown handle reply = %q", reply)
+	}
+
+	tp.mutex.RLock()
+	defer tp.mutex.RUnlock()
+	poll := tp.polls["room"]
+	if got, want := poll.HasVoted, []string{"U2", "zed"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("voters = %q, want %q", got, want)
+	}
+	var actions []string
+	for _, r := range poll.Audit[1:] {
+		actions = append(actions, r.Actor+" "+r.Action)
+	}
+	if want := []string{"root voted for option 1 on behalf of U2", "root voted for option 2 on behalf of zed"}; !reflect.DeepEqual(actions, want) {
+		t.Errorf("audit = %q, want %q", actions, want)
+	}
+}